@@ -0,0 +1,215 @@
+package api
+
+
+import (
+    "bytes"
+    "fmt"
+    "go/format"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+
+// The client package is generated from openapi.json, one method per
+// operation. Regenerate it after changing the API with `go generate ./client`.
+
+
+// Go source for the generated half of the client package
+func GenerateClient() ([]byte, error) {
+    s, err := loadSpec()
+    if err != nil {
+        return nil, err
+    }
+    b := &bytes.Buffer{}
+    paths := []string{}
+    for path := range s.Paths {
+        paths = append(paths, path)
+    }
+    sort.Strings(paths)
+    for _, path := range paths {
+        methods := []string{}
+        for method := range s.Paths[path] {
+            methods = append(methods, method)
+        }
+        sort.Strings(methods)
+        for _, method := range methods {
+            err := writeOperation(b, path, strings.ToUpper(method), s.Paths[path][method])
+            if err != nil {
+                return nil, err
+            }
+        }
+    }
+    // Only import what the operations ended up using
+    header := &bytes.Buffer{}
+    fmt.Fprintln(header, "// Code generated by `go run . generate-client`. DO NOT EDIT.")
+    fmt.Fprintln(header)
+    fmt.Fprintln(header, "package client")
+    fmt.Fprintln(header)
+    fmt.Fprintln(header, "import (")
+    for _, pkg := range []string{"context", "encoding/json", "net/url", "strconv", "strings"} {
+        if strings.Contains(b.String(), pkg[strings.LastIndex(pkg, "/")+1:]+".") {
+            fmt.Fprintf(header, "%q\n", pkg)
+        }
+    }
+    fmt.Fprintln(header)
+    fmt.Fprintln(header, `"github.com/charles-uno/mtgserver/api"`)
+    fmt.Fprintln(header, ")")
+    header.Write(b.Bytes())
+    return format.Source(header.Bytes())
+}
+
+
+func exportedName(s string) string {
+    return strings.ToUpper(s[:1]) + s[1:]
+}
+
+
+func writeOperation(b *bytes.Buffer, path string, method string, op operation) error {
+    name := exportedName(op.OperationID)
+    // A stream isn't one reply to decode, so the client leaves it out. Use
+    // the non-streaming version of the endpoint instead.
+    for _, resp := range op.Responses {
+        if _, ok := resp.Content["text/event-stream"]; ok {
+            return nil
+        }
+    }
+    // The first success response says what comes back. Any other status with
+    // the same body, like a rejected deck upload, isn't an error either.
+    statuses := []string{}
+    for status := range op.Responses {
+        statuses = append(statuses, status)
+    }
+    sort.Strings(statuses)
+    outType, okStatuses := "", []string{}
+    for _, status := range statuses {
+        resp := op.Responses[status]
+        if resp.Ref != "" || status == "default" {
+            continue
+        }
+        t, err := responseType(resp)
+        if err != nil {
+            return fmt.Errorf("%s: %w", op.OperationID, err)
+        }
+        if outType == "" {
+            outType = t
+        }
+        if t == outType {
+            okStatuses = append(okStatuses, status)
+        }
+    }
+    if outType == "" {
+        return fmt.Errorf("%s: no success response", op.OperationID)
+    }
+    // Path parameters come right after the context, in order
+    args := []string{"ctx context.Context"}
+    pathExpr := strconv.Quote(path)
+    query := []parameter{}
+    for _, p := range op.Parameters {
+        switch p.In {
+            case "path":
+                if p.Schema.Type != "string" {
+                    return fmt.Errorf("%s: path parameters must be strings", op.OperationID)
+                }
+                args = append(args, p.Name+" string")
+                hole := strconv.Quote("{" + p.Name + "}")
+                pathExpr = fmt.Sprintf("strings.Replace(%s, %s, url.PathEscape(%s), 1)", pathExpr, hole, p.Name)
+            case "query":
+                query = append(query, p)
+            default:
+                return fmt.Errorf("%s: can't handle %s parameters", op.OperationID, p.In)
+        }
+    }
+    bodyArg := "nil"
+    if op.RequestBody != nil {
+        t, err := bodyType(op.RequestBody)
+        if err != nil {
+            return fmt.Errorf("%s: %w", op.OperationID, err)
+        }
+        args = append(args, "body "+t)
+        bodyArg = "body"
+    }
+    queryArg := "nil"
+    if len(query) > 0 {
+        fmt.Fprintf(b, "\n// Query parameters for %s. Zero values are left out.\n", name)
+        fmt.Fprintf(b, "type %sParams struct {\n", name)
+        for _, p := range query {
+            t, err := paramType(p.Schema)
+            if err != nil {
+                return fmt.Errorf("%s: %w", op.OperationID, err)
+            }
+            fmt.Fprintf(b, "%s %s\n", exportedName(p.Name), t)
+        }
+        fmt.Fprintln(b, "}")
+        args = append(args, "params "+name+"Params")
+        queryArg = "query"
+    }
+    fmt.Fprintf(b, "\n// %s\n", op.Summary)
+    fmt.Fprintf(b, "func (self *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), outType)
+    if len(query) > 0 {
+        fmt.Fprintln(b, "query := url.Values{}")
+        for _, p := range query {
+            field := "params." + exportedName(p.Name)
+            if p.Schema.Type == "integer" {
+                fmt.Fprintf(b, "if %s != nil {\nquery.Set(%q, strconv.FormatInt(*%s, 10))\n}\n", field, p.Name, field)
+            } else {
+                fmt.Fprintf(b, "if %s != \"\" {\nquery.Set(%q, %s)\n}\n", field, p.Name, field)
+            }
+        }
+    }
+    fmt.Fprintf(b, "var out %s\n", outType)
+    fmt.Fprintf(b, "err := self.do(ctx, %q, %s, %s, %s, &out, %s)\n", method, pathExpr, queryArg, bodyArg, strings.Join(okStatuses, ", "))
+    fmt.Fprintln(b, "return out, err")
+    fmt.Fprintln(b, "}")
+    return nil
+}
+
+
+func schemaGoType(sch *schema) (string, error) {
+    if sch == nil {
+        return "json.RawMessage", nil
+    }
+    if sch.Ref != "" {
+        return "api." + refName(sch.Ref), nil
+    }
+    if sch.Type == "string" {
+        return "string", nil
+    }
+    return "", fmt.Errorf("no Go type for inline %s schema", sch.Type)
+}
+
+
+func responseType(resp response) (string, error) {
+    mt, ok := resp.Content["application/json"]
+    if !ok {
+        return "", fmt.Errorf("response isn't JSON")
+    }
+    return schemaGoType(mt.Schema)
+}
+
+
+// JSON bodies are structs. Plain text bodies are strings.
+func bodyType(rb *requestBody) (string, error) {
+    if mt, ok := rb.Content["application/json"]; ok {
+        return schemaGoType(mt.Schema)
+    }
+    if _, ok := rb.Content["text/plain"]; ok {
+        return "string", nil
+    }
+    return "", fmt.Errorf("can't handle request body")
+}
+
+
+// Optional integers are pointers, so zero can still be sent
+func paramType(sch schema) (string, error) {
+    switch sch.Type {
+        case "string":
+            return "string", nil
+        case "integer":
+            if sch.Format != "int64" {
+                return "", fmt.Errorf("integer parameters need format int64")
+            }
+            return "*int64", nil
+    }
+    return "", fmt.Errorf("can't handle %s parameters", strconv.Quote(sch.Type))
+}
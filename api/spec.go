@@ -0,0 +1,307 @@
+package api
+
+
+import (
+    _ "embed"
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "sort"
+    "strings"
+    "time"
+)
+
+
+// The OpenAPI description is written by hand. Its schemas are checked against
+// the Go types here, field by field, so the two can't drift apart quietly.
+
+
+//go:embed openapi.json
+var Spec []byte
+
+
+// The Go type behind each schema in openapi.json
+var schemaTypes = map[string]reflect.Type{
+    "Decks": reflect.TypeOf(Decks{}),
+    "DeckReport": reflect.TypeOf(DeckReport{}),
+    "Decklist": reflect.TypeOf(Decklist{}),
+    "OpeningHand": reflect.TypeOf(OpeningHand{}),
+    "OpponentProfile": reflect.TypeOf(OpponentProfile{}),
+    "Disruption": reflect.TypeOf(Disruption{}),
+    "SideboardRequest": reflect.TypeOf(SideboardRequest{}),
+    "GameResult": reflect.TypeOf(GameResult{}),
+    "SearchStats": reflect.TypeOf(SearchStats{}),
+    "PlayTag": reflect.TypeOf(PlayTag{}),
+    "Event": reflect.TypeOf(Event{}),
+    "ErrorReply": reflect.TypeOf(ErrorReply{}),
+    "Error": reflect.TypeOf(Error{}),
+    "JobRequest": reflect.TypeOf(JobRequest{}),
+    "SimSpec": reflect.TypeOf(SimSpec{}),
+    "JobStatus": reflect.TypeOf(JobStatus{}),
+    "SimResult": reflect.TypeOf(SimResult{}),
+    "SimTally": reflect.TypeOf(SimTally{}),
+    "TurnProgress": reflect.TypeOf(TurnProgress{}),
+}
+
+
+// Just enough of OpenAPI 3 to check schemas and generate the client
+type spec struct {
+    Paths map[string]map[string]operation   `json:"paths"`
+    Components struct {
+        Responses map[string]response       `json:"responses"`
+        Schemas map[string]schema           `json:"schemas"`
+    }                                       `json:"components"`
+}
+
+
+type operation struct {
+    OperationID string              `json:"operationId"`
+    Summary string                  `json:"summary"`
+    Parameters []parameter          `json:"parameters"`
+    RequestBody *requestBody        `json:"requestBody"`
+    Responses map[string]response   `json:"responses"`
+}
+
+
+type parameter struct {
+    Name string     `json:"name"`
+    In string       `json:"in"`
+    Required bool   `json:"required"`
+    Schema schema   `json:"schema"`
+}
+
+
+type requestBody struct {
+    Content map[string]mediaType    `json:"content"`
+}
+
+
+type response struct {
+    Ref string                      `json:"$ref"`
+    Content map[string]mediaType    `json:"content"`
+}
+
+
+type mediaType struct {
+    Schema *schema  `json:"schema"`
+}
+
+
+type schema struct {
+    Ref string                      `json:"$ref"`
+    Type string                     `json:"type"`
+    Format string                   `json:"format"`
+    Properties map[string]schema    `json:"properties"`
+    Required []string               `json:"required"`
+    Items *schema                   `json:"items"`
+    AdditionalProperties *schema    `json:"additionalProperties"`
+}
+
+
+func loadSpec() (spec, error) {
+    s := spec{}
+    err := json.Unmarshal(Spec, &s)
+    return s, err
+}
+
+
+func refName(ref string) string {
+    return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+
+// Make sure every schema matches its Go type and every reference points
+// somewhere. Returns all the problems rather than stopping at the first.
+func CheckSpec() []error {
+    s, err := loadSpec()
+    if err != nil {
+        return []error{fmt.Errorf("openapi.json: %w", err)}
+    }
+    errs := []error{}
+    for name, t := range schemaTypes {
+        sch, ok := s.Components.Schemas[name]
+        if !ok {
+            errs = append(errs, fmt.Errorf("openapi.json: no schema for %s", name))
+            continue
+        }
+        errs = append(errs, checkSchema(name, sch, t)...)
+    }
+    for name := range s.Components.Schemas {
+        if _, ok := schemaTypes[name]; !ok {
+            errs = append(errs, fmt.Errorf("openapi.json: schema %s has no Go type", name))
+        }
+    }
+    for path, ops := range s.Paths {
+        for method, op := range ops {
+            where := strings.ToUpper(method) + " " + path
+            if op.OperationID == "" {
+                errs = append(errs, fmt.Errorf("openapi.json: %s has no operationId", where))
+            }
+            for status, resp := range op.Responses {
+                if resp.Ref != "" {
+                    if _, ok := s.Components.Responses[refName(resp.Ref)]; !ok {
+                        errs = append(errs, fmt.Errorf("openapi.json: %s %s: no response %s", where, status, resp.Ref))
+                    }
+                    continue
+                }
+                errs = append(errs, checkRefs(where+" "+status, resp.Content)...)
+            }
+            if op.RequestBody != nil {
+                errs = append(errs, checkRefs(where+" body", op.RequestBody.Content)...)
+            }
+        }
+    }
+    for name, resp := range s.Components.Responses {
+        errs = append(errs, checkRefs("response "+name, resp.Content)...)
+    }
+    // Map order is random. Keep the output stable.
+    sort.Slice(errs, func(i, j int) bool {
+        return errs[i].Error() < errs[j].Error()
+    })
+    return errs
+}
+
+
+func checkRefs(where string, content map[string]mediaType) []error {
+    errs := []error{}
+    for _, mt := range content {
+        if mt.Schema == nil || mt.Schema.Ref == "" {
+            continue
+        }
+        if _, ok := schemaTypes[refName(mt.Schema.Ref)]; !ok {
+            errs = append(errs, fmt.Errorf("openapi.json: %s: no schema %s", where, mt.Schema.Ref))
+        }
+    }
+    return errs
+}
+
+
+func checkSchema(where string, sch schema, t reflect.Type) []error {
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    if sch.Ref != "" {
+        want, ok := schemaTypes[refName(sch.Ref)]
+        if !ok || want != t {
+            return []error{fmt.Errorf("openapi.json: %s: %s is not %s", where, sch.Ref, t)}
+        }
+        return nil
+    }
+    mismatch := []error{fmt.Errorf("openapi.json: %s: type %q doesn't match %s", where, sch.Type, t)}
+    // Times are marshaled as RFC 3339 strings
+    if t == reflect.TypeOf(time.Time{}) {
+        if sch.Type != "string" || sch.Format != "date-time" {
+            return mismatch
+        }
+        return nil
+    }
+    switch t.Kind() {
+        case reflect.String:
+            if sch.Type != "string" {
+                return mismatch
+            }
+        case reflect.Bool:
+            if sch.Type != "boolean" {
+                return mismatch
+            }
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+            reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            if sch.Type != "integer" {
+                return mismatch
+            }
+        case reflect.Float32, reflect.Float64:
+            if sch.Type != "number" {
+                return mismatch
+            }
+        case reflect.Slice:
+            if sch.Type != "array" || sch.Items == nil {
+                return mismatch
+            }
+            return checkSchema(where+"[]", *sch.Items, t.Elem())
+        case reflect.Map:
+            if sch.Type != "object" || sch.AdditionalProperties == nil {
+                return mismatch
+            }
+            return checkSchema(where+"{}", *sch.AdditionalProperties, t.Elem())
+        case reflect.Struct:
+            if sch.Type != "object" {
+                return mismatch
+            }
+            return checkFields(where, sch, t)
+        default:
+            return []error{fmt.Errorf("openapi.json: %s: can't describe %s", where, t)}
+    }
+    return nil
+}
+
+
+type jsonField struct {
+    name string
+    t reflect.Type
+    omitEmpty bool
+}
+
+
+// Fields as encoding/json sees them, with embedded structs flattened
+func jsonFields(t reflect.Type) []jsonField {
+    fields := []jsonField{}
+    for i := 0; i < t.NumField(); i++ {
+        f := t.Field(i)
+        tag := f.Tag.Get("json")
+        if tag == "-" || f.PkgPath != "" && !f.Anonymous {
+            continue
+        }
+        name, opts := tag, ""
+        if i := strings.Index(tag, ","); i >= 0 {
+            name, opts = tag[:i], tag[i:]
+        }
+        if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+            fields = append(fields, jsonFields(f.Type)...)
+            continue
+        }
+        if name == "" {
+            name = f.Name
+        }
+        fields = append(fields, jsonField{
+            name: name,
+            t: f.Type,
+            omitEmpty: strings.Contains(opts, ",omitempty"),
+        })
+    }
+    return fields
+}
+
+
+// Properties have to match the fields exactly. A property is required if and
+// only if the field is always written out, which is to say not omitempty.
+func checkFields(where string, sch schema, t reflect.Type) []error {
+    errs := []error{}
+    required := map[string]bool{}
+    for _, name := range sch.Required {
+        required[name] = true
+    }
+    seen := map[string]bool{}
+    for _, f := range jsonFields(t) {
+        seen[f.name] = true
+        prop, ok := sch.Properties[f.name]
+        if !ok {
+            errs = append(errs, fmt.Errorf("openapi.json: %s: no property for field %s", where, f.name))
+            continue
+        }
+        if required[f.name] == f.omitEmpty {
+            errs = append(errs, fmt.Errorf("openapi.json: %s.%s: required should be %t", where, f.name, !f.omitEmpty))
+        }
+        errs = append(errs, checkSchema(where+"."+f.name, prop, f.t)...)
+    }
+    for name := range sch.Properties {
+        if !seen[name] {
+            errs = append(errs, fmt.Errorf("openapi.json: %s: property %s has no field", where, name))
+        }
+    }
+    for name := range required {
+        if !seen[name] {
+            errs = append(errs, fmt.Errorf("openapi.json: %s: required %s has no field", where, name))
+        }
+    }
+    return errs
+}
@@ -0,0 +1,32 @@
+package api
+
+
+import (
+    "bytes"
+    "os"
+    "testing"
+)
+
+
+func TestCheckSpec(t *testing.T) {
+    for _, err := range CheckSpec() {
+        t.Error(err)
+    }
+}
+
+
+// The client is checked in, so it has to be regenerated whenever the spec
+// changes: go run . generate-client -o client/client_gen.go
+func TestClientUpToDate(t *testing.T) {
+    want, err := GenerateClient()
+    if err != nil {
+        t.Fatal(err)
+    }
+    got, err := os.ReadFile("../client/client_gen.go")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !bytes.Equal(got, want) {
+        t.Error("client/client_gen.go is out of date with openapi.json")
+    }
+}
@@ -0,0 +1,116 @@
+package api
+
+
+import (
+    "fmt"
+
+    "github.com/charles-uno/mtgserver/lib"
+)
+
+
+// Request and response bodies for the /v1 API. The server and the generated
+// client both use these, and openapi.json describes them. `go run . validate`
+// checks that the description still matches.
+
+
+type OpeningHand struct {
+    Deck        string      `json:"deck,omitempty"`
+    Hand        []string    `json:"hand"`
+    Library     []string    `json:"library"`
+    OnThePlay   bool        `json:"onThePlay"`
+    Verbose     bool        `json:"verbose"`
+    // Games 2 and 3 are post-board, so the opponent may have hate pieces
+    Game        int         `json:"game,omitempty"`
+    // Either the name of a profile from opponents.yaml or a full profile.
+    // Leave both empty to goldfish.
+    Opponent        string              `json:"opponent,omitempty"`
+    OpponentProfile *OpponentProfile    `json:"opponentProfile,omitempty"`
+    // breadth-first (the default) or best-first
+    Search          string              `json:"search,omitempty"`
+    // Seed for shuffling the library and rolling the opponent's cards. Leave
+    // empty for a random one.
+    Seed            *int64              `json:"seed,omitempty"`
+    // Ask for less search than the server default. Zero means the default.
+    Turns           int                 `json:"turns,omitempty"`
+    TimeoutMillis   int                 `json:"timeoutMillis,omitempty"`
+}
+
+
+func (oh *OpeningHand) Profile() (OpponentProfile, error) {
+    if oh.OpponentProfile != nil {
+        return oh.OpponentProfile.ForGame(oh.Game)
+    }
+    if oh.Opponent == "" {
+        return OpponentProfile{}, nil
+    }
+    profile, err := lib.LoadOpponent(oh.Opponent)
+    if err != nil {
+        return profile, err
+    }
+    return profile.ForGame(oh.Game)
+}
+
+
+type SideboardRequest struct {
+    lib.SideboardPlan
+    Game        int         `json:"game"`
+    Opponent    string      `json:"opponent,omitempty"`
+    // Leave empty to flip a coin
+    OnThePlay   *bool       `json:"onThePlay,omitempty"`
+}
+
+
+type Decks struct {
+    Decks       []string    `json:"decks"`
+    Default     string      `json:"default"`
+}
+
+
+type DeckReport struct {
+    ID          string      `json:"id,omitempty"`
+    Problems    []string    `json:"problems"`
+}
+
+
+type ErrorReply struct {
+    Error       Error       `json:"error"`
+}
+
+
+type Error struct {
+    Status      int         `json:"status"`
+    Message     string      `json:"message"`
+    // Close matches, when the error is an unknown card name
+    Suggestions []string    `json:"suggestions,omitempty"`
+}
+
+
+func (self *Error) Error() string {
+    return fmt.Sprintf("%d: %s", self.Status, self.Message)
+}
+
+
+// A simulation to run in the background, with the same search limits a game
+// can ask for
+type JobRequest struct {
+    lib.SimSpec
+    Turns           int                 `json:"turns,omitempty"`
+    TimeoutMillis   int                 `json:"timeoutMillis,omitempty"`
+}
+
+
+// These come straight from the engine
+type (
+    Decklist = lib.Decklist
+    OpponentProfile = lib.OpponentProfile
+    Disruption = lib.Disruption
+    GameResult = lib.GameResult
+    SearchStats = lib.SearchStats
+    PlayTag = lib.PlayTag
+    Event = lib.Event
+    SimSpec = lib.SimSpec
+    SimResult = lib.SimResult
+    SimTally = lib.SimTally
+    JobStatus = lib.JobStatus
+    TurnProgress = lib.TurnProgress
+)
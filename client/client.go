@@ -0,0 +1,85 @@
+package client
+
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+
+    "github.com/charles-uno/mtgserver/api"
+)
+
+
+// Go client for the /v1 API, for scripts and internal tools. The methods are
+// generated from openapi.json into client_gen.go. This file has the plumbing
+// they share.
+
+//go:generate go run .. generate-client -o client_gen.go
+
+
+type Client struct {
+    // Like http://localhost:5001, with no trailing slash
+    BaseURL string
+    HTTP *http.Client
+}
+
+
+func New(baseURL string) *Client {
+    return &Client{
+        BaseURL: strings.TrimSuffix(baseURL, "/"),
+        HTTP: http.DefaultClient,
+    }
+}
+
+
+// Send a request and decode the reply into out. A string body goes as plain
+// text, anything else as JSON. Statuses other than ok come back as *api.Error.
+func (self *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}, ok ...int) error {
+    target := self.BaseURL + path
+    if len(query) > 0 {
+        target += "?" + query.Encode()
+    }
+    var reader io.Reader
+    contentType := ""
+    switch b := body.(type) {
+        case nil:
+        case string:
+            reader = strings.NewReader(b)
+            contentType = "text/plain"
+        default:
+            raw, err := json.Marshal(b)
+            if err != nil {
+                return err
+            }
+            reader = bytes.NewReader(raw)
+            contentType = "application/json"
+    }
+    req, err := http.NewRequestWithContext(ctx, method, target, reader)
+    if err != nil {
+        return err
+    }
+    if contentType != "" {
+        req.Header.Set("Content-Type", contentType)
+    }
+    resp, err := self.HTTP.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    for _, status := range ok {
+        if resp.StatusCode == status {
+            return json.NewDecoder(resp.Body).Decode(out)
+        }
+    }
+    reply := api.ErrorReply{}
+    err = json.NewDecoder(resp.Body).Decode(&reply)
+    if err != nil || reply.Error.Message == "" {
+        return fmt.Errorf("%s %s: unexpected status %d", method, path, resp.StatusCode)
+    }
+    return &reply.Error
+}
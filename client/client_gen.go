@@ -0,0 +1,172 @@
+// Code generated by `go run . generate-client`. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/charles-uno/mtgserver/api"
+)
+
+// List the decks that can be passed as deck
+func (self *Client) ListDecks(ctx context.Context) (api.Decks, error) {
+	var out api.Decks
+	err := self.do(ctx, "GET", "/v1/decks", nil, nil, &out, 200)
+	return out, err
+}
+
+// Query parameters for UploadDeck. Zero values are left out.
+type UploadDeckParams struct {
+	Format string
+}
+
+// Check a decklist and save it if there are no problems
+func (self *Client) UploadDeck(ctx context.Context, body string, params UploadDeckParams) (api.DeckReport, error) {
+	query := url.Values{}
+	if params.Format != "" {
+		query.Set("format", params.Format)
+	}
+	var out api.DeckReport
+	err := self.do(ctx, "POST", "/v1/decks", query, body, &out, 201, 422)
+	return out, err
+}
+
+// Query parameters for EndToEnd. Zero values are left out.
+type EndToEndParams struct {
+	Deck          string
+	Opponent      string
+	Game          *int64
+	Search        string
+	Seed          *int64
+	Turns         *int64
+	TimeoutMillis *int64
+	Format        string
+}
+
+// Deal a hand and play it out in one go
+func (self *Client) EndToEnd(ctx context.Context, params EndToEndParams) (api.GameResult, error) {
+	query := url.Values{}
+	if params.Deck != "" {
+		query.Set("deck", params.Deck)
+	}
+	if params.Opponent != "" {
+		query.Set("opponent", params.Opponent)
+	}
+	if params.Game != nil {
+		query.Set("game", strconv.FormatInt(*params.Game, 10))
+	}
+	if params.Search != "" {
+		query.Set("search", params.Search)
+	}
+	if params.Seed != nil {
+		query.Set("seed", strconv.FormatInt(*params.Seed, 10))
+	}
+	if params.Turns != nil {
+		query.Set("turns", strconv.FormatInt(*params.Turns, 10))
+	}
+	if params.TimeoutMillis != nil {
+		query.Set("timeoutMillis", strconv.FormatInt(*params.TimeoutMillis, 10))
+	}
+	if params.Format != "" {
+		query.Set("format", params.Format)
+	}
+	var out api.GameResult
+	err := self.do(ctx, "GET", "/v1/e2e", query, nil, &out, 200)
+	return out, err
+}
+
+// Query parameters for DealHand. Zero values are left out.
+type DealHandParams struct {
+	Deck string
+}
+
+// Shuffle a deck and deal an opening hand
+func (self *Client) DealHand(ctx context.Context, params DealHandParams) (api.OpeningHand, error) {
+	query := url.Values{}
+	if params.Deck != "" {
+		query.Set("deck", params.Deck)
+	}
+	var out api.OpeningHand
+	err := self.do(ctx, "GET", "/v1/hand", query, nil, &out, 200)
+	return out, err
+}
+
+// Start a simulation in the background
+func (self *Client) SubmitJob(ctx context.Context, body api.JobRequest) (api.JobStatus, error) {
+	var out api.JobStatus
+	err := self.do(ctx, "POST", "/v1/jobs", nil, body, &out, 202)
+	return out, err
+}
+
+// Stop a simulation, keeping results so far
+func (self *Client) CancelJob(ctx context.Context, id string) (api.JobStatus, error) {
+	var out api.JobStatus
+	err := self.do(ctx, "DELETE", strings.Replace("/v1/jobs/{id}", "{id}", url.PathEscape(id), 1), nil, nil, &out, 200)
+	return out, err
+}
+
+// Check on a simulation, with results so far
+func (self *Client) GetJob(ctx context.Context, id string) (api.JobStatus, error) {
+	var out api.JobStatus
+	err := self.do(ctx, "GET", strings.Replace("/v1/jobs/{id}", "{id}", url.PathEscape(id), 1), nil, nil, &out, 200)
+	return out, err
+}
+
+// This document
+func (self *Client) GetOpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := self.do(ctx, "GET", "/v1/openapi.json", nil, nil, &out, 200)
+	return out, err
+}
+
+// Query parameters for Play. Zero values are left out.
+type PlayParams struct {
+	Format string
+}
+
+// Play out an opening hand
+func (self *Client) Play(ctx context.Context, body api.OpeningHand, params PlayParams) (api.GameResult, error) {
+	query := url.Values{}
+	if params.Format != "" {
+		query.Set("format", params.Format)
+	}
+	var out api.GameResult
+	err := self.do(ctx, "POST", "/v1/play", query, body, &out, 200)
+	return out, err
+}
+
+// Query parameters for GetDecklist. Zero values are left out.
+type GetDecklistParams struct {
+	Deck string
+}
+
+// Show a decklist, sideboard included
+func (self *Client) GetDecklist(ctx context.Context, params GetDecklistParams) (api.Decklist, error) {
+	query := url.Values{}
+	if params.Deck != "" {
+		query.Set("deck", params.Deck)
+	}
+	var out api.Decklist
+	err := self.do(ctx, "GET", "/v1/sideboard", query, nil, &out, 200)
+	return out, err
+}
+
+// Query parameters for Sideboard. Zero values are left out.
+type SideboardParams struct {
+	Deck string
+}
+
+// Deal an opening hand for game 2 or 3 from the post-board deck
+func (self *Client) Sideboard(ctx context.Context, body api.SideboardRequest, params SideboardParams) (api.OpeningHand, error) {
+	query := url.Values{}
+	if params.Deck != "" {
+		query.Set("deck", params.Deck)
+	}
+	var out api.OpeningHand
+	err := self.do(ctx, "POST", "/v1/sideboard", query, body, &out, 200)
+	return out, err
+}
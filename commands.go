@@ -0,0 +1,141 @@
+package main
+
+import (
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "log/slog"
+    "os"
+
+    "github.com/charles-uno/mtgserver/api"
+    "github.com/charles-uno/mtgserver/lib"
+)
+
+
+// Offline maintenance commands, as opposed to running the server. These are
+// invoked as the first argument, like `go run . import-scryfall`.
+var commands = map[string]func([]string) error{
+    "bench": bench,
+    "generate-client": generateClient,
+    "import-scryfall": importScryfall,
+    "validate": validate,
+}
+
+
+func runCommand(args []string) {
+    cmd, ok := commands[args[0]]
+    if !ok {
+        fmt.Fprintln(os.Stderr, "unknown command:", args[0])
+        os.Exit(2)
+    }
+    err := cmd(args[1:])
+    if err != nil {
+        log.Fatal(err)
+    }
+}
+
+
+func importScryfall(args []string) error {
+    fs := flag.NewFlagSet("import-scryfall", flag.ExitOnError)
+    bulk := fs.String("bulk", "oracle-cards.json", "Scryfall bulk data file")
+    cardData := fs.String("carddata", "carddata.yaml", "card data file to update")
+    out := fs.String("o", "", "where to write the updated card data (default stdout)")
+    overwrite := fs.Bool("overwrite", false, "prefer Scryfall over hand-written values")
+    fs.Parse(args)
+    updated, report, err := lib.ImportScryfall(*bulk, *cardData, *overwrite)
+    if err != nil {
+        return err
+    }
+    // Keep stdout clean for the YAML. The report goes to stderr.
+    b, _ := json.MarshalIndent(report, "", "  ")
+    fmt.Fprintln(os.Stderr, string(b))
+    if *out == "" {
+        _, err = os.Stdout.Write(updated)
+        return err
+    }
+    return ioutil.WriteFile(*out, updated, 0644)
+}
+
+
+// Time the engine on a fixed set of hands
+func bench(args []string) error {
+    fs := flag.NewFlagSet("bench", flag.ExitOnError)
+    deck := fs.String("deck", lib.DefaultDeck, "deck to deal hands from")
+    hands := fs.Int("hands", 50, "how many hands to play")
+    seed := fs.Int64("seed", 1, "seed for the first hand; the rest count up from there")
+    turns := fs.Int("turns", 4, "how many turns to play before giving up")
+    search := fs.String("search", lib.SearchBreadthFirst, "search to use, breadth-first or best-first")
+    fs.Parse(args)
+    // The engine logs every turn, which would drown out the report
+    logger := slog.Default()
+    slog.SetDefault(slog.New(slog.NewTextHandler(ioutil.Discard, nil)))
+    report, err := lib.Benchmark(*deck, *hands, *seed, *turns, *search)
+    slog.SetDefault(logger)
+    if err != nil {
+        return err
+    }
+    b, _ := json.MarshalIndent(report, "", "  ")
+    fmt.Println(string(b))
+    return nil
+}
+
+
+// Write the client package's methods from the OpenAPI description
+func generateClient(args []string) error {
+    fs := flag.NewFlagSet("generate-client", flag.ExitOnError)
+    out := fs.String("o", "", "where to write the client code (default stdout)")
+    fs.Parse(args)
+    src, err := api.GenerateClient()
+    if err != nil {
+        return err
+    }
+    if *out == "" {
+        _, err = os.Stdout.Write(src)
+        return err
+    }
+    return ioutil.WriteFile(*out, src, 0644)
+}
+
+
+// Check card data, every decklist, and the API description, printing all
+// problems rather than stopping at the first
+func validate(args []string) error {
+    fs := flag.NewFlagSet("validate", flag.ExitOnError)
+    fs.Parse(args)
+    nProblems := 0
+    for _, err := range api.CheckSpec() {
+        fmt.Println(err)
+        nProblems += 1
+    }
+    for _, err := range lib.ValidateCardData() {
+        fmt.Println(err)
+        nProblems += 1
+    }
+    // Decklists can't be checked against broken card data
+    if nProblems > 0 {
+        return fmt.Errorf("found %d problems in card data or the API description", nProblems)
+    }
+    names, err := lib.ListDecks()
+    if err != nil {
+        return err
+    }
+    for _, name := range names {
+        deck, err := lib.LoadDecklist(name)
+        if err != nil {
+            fmt.Println(err)
+            nProblems += 1
+            continue
+        }
+        for _, problem := range lib.ValidateDecklist(deck) {
+            fmt.Println(name + ": " + problem)
+            nProblems += 1
+        }
+    }
+    if nProblems > 0 {
+        return fmt.Errorf("found %d problems", nProblems)
+    }
+    fmt.Println("card data, the API description, and", len(names), "decklists look good")
+    return nil
+}
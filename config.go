@@ -0,0 +1,237 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "io/ioutil"
+    "log/slog"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/charles-uno/mtgserver/lib"
+    "github.com/charles-uno/mtgserver/service"
+    "gopkg.in/yaml.v2"
+)
+
+
+// Settings for the running server, loaded once at startup
+var cfg = defaultConfig()
+
+
+// Server settings come from, in increasing order of precedence: the defaults
+// below, a YAML file passed as --config (or MTGSERVER_CONFIG), environment
+// variables named like MTGSERVER_MAX_TURNS, and command line flags.
+type config struct {
+    Listen      string          `yaml:"listen"`
+    // The gRPC server only runs if this is set
+    GRPCListen  string          `yaml:"grpc_listen"`
+    // Origins allowed to call the API from a browser. "*" allows any.
+    CORSOrigins []string        `yaml:"cors_origins"`
+    DataDir     string          `yaml:"data_dir"`
+    Watch       time.Duration   `yaml:"watch"`
+    // trace, debug, info, warn, or error. At debug, the search logs every
+    // turn. At trace, it logs every line it gives up on.
+    LogLevel    string          `yaml:"log_level"`
+    // json or text
+    LogFormat   string          `yaml:"log_format"`
+    // Print a colored replay of every game to stdout
+    ShowGames   bool            `yaml:"show_games"`
+    // Serve Go's profiler at /debug/pprof
+    PProf       bool            `yaml:"pprof"`
+    // Requests can ask for fewer turns or a shorter timeout, but not more
+    DefaultTurns    int             `yaml:"default_turns"`
+    MaxTurns        int             `yaml:"max_turns"`
+    Timeout         time.Duration   `yaml:"timeout"`
+    MaxTimeout      time.Duration   `yaml:"max_timeout"`
+    ManaCap         int             `yaml:"mana_cap"`
+    MaxExpanded     int             `yaml:"max_expanded"`
+    // Simulations running at once, and how many to keep track of in total
+    JobWorkers      int             `yaml:"job_workers"`
+    MaxJobs         int             `yaml:"max_jobs"`
+    MaxTrials       int             `yaml:"max_trials"`
+    // On SIGTERM, how long to let requests and jobs finish before cutting
+    // them off
+    ShutdownTimeout time.Duration   `yaml:"shutdown_timeout"`
+}
+
+
+func defaultConfig() config {
+    budget := lib.DefaultBudget()
+    return config{
+        Listen: ":5001",
+        CORSOrigins: []string{"*"},
+        DataDir: ".",
+        Watch: 2 * time.Second,
+        LogLevel: "info",
+        LogFormat: "json",
+        DefaultTurns: budget.MaxTurns,
+        MaxTurns: 6,
+        Timeout: budget.Timeout,
+        MaxTimeout: 30 * time.Second,
+        ManaCap: budget.ManaCap,
+        MaxExpanded: budget.MaxExpanded,
+        JobWorkers: 2,
+        MaxJobs: 100,
+        MaxTrials: 1000,
+        ShutdownTimeout: 30 * time.Second,
+    }
+}
+
+
+// Comma-separated on the command line and in the environment
+type stringList []string
+
+
+func (self *stringList) String() string {
+    return strings.Join(*self, ",")
+}
+
+
+func (self *stringList) Set(s string) error {
+    *self = strings.Split(s, ",")
+    return nil
+}
+
+
+func (self *config) flagSet(configFile *string) *flag.FlagSet {
+    fs := flag.NewFlagSet("mtgserver", flag.ExitOnError)
+    fs.StringVar(configFile, "config", *configFile, "YAML file with server settings")
+    fs.StringVar(&self.Listen, "listen", self.Listen, "address to serve on")
+    fs.StringVar(&self.GRPCListen, "grpc-listen", self.GRPCListen, "address to serve gRPC on, or empty for no gRPC")
+    fs.Var((*stringList)(&self.CORSOrigins), "cors-origins", "comma-separated origins allowed to call the API, or * for any")
+    fs.StringVar(&self.DataDir, "data-dir", self.DataDir, "directory with card data and decklists, overriding the built-in ones")
+    fs.DurationVar(&self.Watch, "watch", self.Watch, "how often to check data files for changes, or 0 to only reload on SIGHUP")
+    fs.StringVar(&self.LogLevel, "log-level", self.LogLevel, "trace, debug, info, warn, or error")
+    fs.StringVar(&self.LogFormat, "log-format", self.LogFormat, "json or text")
+    fs.BoolVar(&self.ShowGames, "show-games", self.ShowGames, "print a colored replay of every game to stdout")
+    fs.BoolVar(&self.PProf, "pprof", self.PProf, "serve profiles at /debug/pprof")
+    fs.IntVar(&self.DefaultTurns, "default-turns", self.DefaultTurns, "turns to play when a request doesn't say")
+    fs.IntVar(&self.MaxTurns, "max-turns", self.MaxTurns, "most turns a request can ask for")
+    fs.DurationVar(&self.Timeout, "timeout", self.Timeout, "search time per game when a request doesn't say")
+    fs.DurationVar(&self.MaxTimeout, "max-timeout", self.MaxTimeout, "most search time a request can ask for")
+    fs.IntVar(&self.ManaCap, "mana-cap", self.ManaCap, "stop playing lands once there's this much mana")
+    fs.IntVar(&self.MaxExpanded, "max-expanded", self.MaxExpanded, "states to expand per game before giving up, or 0 for no limit")
+    fs.IntVar(&self.JobWorkers, "job-workers", self.JobWorkers, "simulation jobs to run at once")
+    fs.IntVar(&self.MaxJobs, "max-jobs", self.MaxJobs, "simulation jobs to keep, queued, running, or finished")
+    fs.IntVar(&self.MaxTrials, "max-trials", self.MaxTrials, "most trials one simulation job can ask for")
+    fs.DurationVar(&self.ShutdownTimeout, "shutdown-timeout", self.ShutdownTimeout, "how long to let requests and jobs finish on SIGTERM")
+    return fs
+}
+
+
+func envName(flagName string) string {
+    return "MTGSERVER_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+
+// Returns the config and whatever arguments are left after the flags
+func loadConfig(args []string) (config, []string, error) {
+    // Parse the flags once up front to find the config file, and again at the
+    // end so they win over everything else
+    scratch := defaultConfig()
+    configFile := os.Getenv(envName("config"))
+    fs := scratch.flagSet(&configFile)
+    fs.Parse(args)
+    rest := fs.Args()
+    explicit := map[string]string{}
+    fs.Visit(func(f *flag.Flag) {
+        explicit[f.Name] = f.Value.String()
+    })
+    ret := defaultConfig()
+    if configFile != "" {
+        text, err := ioutil.ReadFile(configFile)
+        if err != nil {
+            return ret, rest, err
+        }
+        err = yaml.UnmarshalStrict(text, &ret)
+        if err != nil {
+            return ret, rest, fmt.Errorf("%s: %w", configFile, err)
+        }
+    }
+    fs = ret.flagSet(&configFile)
+    var err error
+    fs.VisitAll(func(f *flag.Flag) {
+        value, ok := os.LookupEnv(envName(f.Name))
+        if ok && err == nil {
+            err = fs.Set(f.Name, value)
+            if err != nil {
+                err = fmt.Errorf("%s=%q: %w", envName(f.Name), value, err)
+            }
+        }
+    })
+    if err != nil {
+        return ret, rest, err
+    }
+    for name, value := range explicit {
+        fs.Set(name, value)
+    }
+    return ret, rest, ret.check()
+}
+
+
+func (self *config) check() error {
+    if _, ok := logLevels[self.LogLevel]; !ok {
+        return fmt.Errorf("unknown log level: %s", self.LogLevel)
+    }
+    if self.LogFormat != "json" && self.LogFormat != "text" {
+        return fmt.Errorf("unknown log format: %s", self.LogFormat)
+    }
+    if self.MaxTurns < 1 || self.DefaultTurns < 1 || self.DefaultTurns > self.MaxTurns {
+        return fmt.Errorf("need 1 <= default turns (%d) <= max turns (%d)", self.DefaultTurns, self.MaxTurns)
+    }
+    if self.Timeout <= 0 || self.Timeout > self.MaxTimeout {
+        return fmt.Errorf("need 0 < timeout (%s) <= max timeout (%s)", self.Timeout, self.MaxTimeout)
+    }
+    if self.ManaCap < 1 {
+        return fmt.Errorf("mana cap must be positive, not %d", self.ManaCap)
+    }
+    if self.MaxExpanded < 0 {
+        return fmt.Errorf("max expanded can't be negative")
+    }
+    if self.JobWorkers < 1 || self.MaxJobs < 1 || self.MaxTrials < 1 {
+        return fmt.Errorf("job workers, max jobs, and max trials must be positive")
+    }
+    return nil
+}
+
+
+func (self *config) limits() service.Limits {
+    return service.Limits{
+        DefaultTurns: self.DefaultTurns,
+        MaxTurns: self.MaxTurns,
+        Timeout: self.Timeout,
+        MaxTimeout: self.MaxTimeout,
+        ManaCap: self.ManaCap,
+        MaxExpanded: self.MaxExpanded,
+        MaxTrials: self.MaxTrials,
+    }
+}
+
+
+var logLevels = map[string]slog.Level{
+    "trace": lib.LevelTrace,
+    "debug": slog.LevelDebug,
+    "info": slog.LevelInfo,
+    "warn": slog.LevelWarn,
+    "error": slog.LevelError,
+}
+
+
+// Logs go to stderr, one JSON object per line, or as key=value text for
+// reading by eye
+func (self *config) logger() *slog.Logger {
+    opts := &slog.HandlerOptions{
+        Level: logLevels[self.LogLevel],
+        ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+            if a.Key == slog.LevelKey && a.Value.Any() == lib.LevelTrace {
+                a.Value = slog.StringValue("TRACE")
+            }
+            return a
+        },
+    }
+    if self.LogFormat == "text" {
+        return slog.New(slog.NewTextHandler(os.Stderr, opts))
+    }
+    return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+    "embed"
+)
+
+
+// Default card data, opponents, and decklists are compiled in, so the server
+// doesn't care where it's launched from. Files in --data-dir take precedence.
+//go:embed carddata.yaml opponents.yaml decks
+var defaultData embed.FS
@@ -0,0 +1,215 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "runtime/debug"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "github.com/charles-uno/mtgserver/api"
+    "github.com/charles-uno/mtgserver/lib"
+    "github.com/charles-uno/mtgserver/service"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+
+// Why the server shouldn't get traffic yet, or "" once it should. Load
+// balancers watch /readyz for this, while /healthz only says the process is up.
+var notReady atomic.Value
+
+
+const shuttingDown = "shutting down"
+
+
+func init() {
+    notReady.Store("loading data files")
+}
+
+
+type health struct {
+    Status      string      `json:"status"`
+    Problem     string      `json:"problem,omitempty"`
+}
+
+
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, health{Status: "ok"})
+}
+
+
+func handleReady(w http.ResponseWriter, r *http.Request) {
+    if problem := notReady.Load().(string); problem != "" {
+        writeJSON(w, http.StatusServiceUnavailable, health{Status: "unavailable", Problem: problem})
+        return
+    }
+    writeJSON(w, http.StatusOK, health{Status: "ok"})
+}
+
+
+// Load card data and every decklist, and only call the server ready if they
+// all check out. Runs at startup and again on SIGHUP.
+func checkReady() {
+    if notReady.Load().(string) == shuttingDown {
+        return
+    }
+    err := checkData()
+    if err != nil {
+        notReady.Store(err.Error())
+        slog.Error("not ready", "err", err)
+        return
+    }
+    notReady.Store("")
+    slog.Info("ready")
+}
+
+
+func checkData() error {
+    err := lib.ReloadCardData()
+    if err != nil {
+        return fmt.Errorf("card data: %w", err)
+    }
+    names, err := lib.ListDecks()
+    if err != nil {
+        return err
+    }
+    for _, name := range names {
+        deck, err := lib.LoadDecklist(name)
+        if err != nil {
+            return err
+        }
+        if problems := lib.ValidateDecklist(deck); len(problems) > 0 {
+            return fmt.Errorf("%s: %s", name, problems[0])
+        }
+    }
+    return nil
+}
+
+
+// A bug in the engine fails the request rather than taking down the server.
+// Once a stream has started, the status is already sent, so the error goes
+// out as an event instead.
+func recoverPanics(handler http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        sw := &startedWriter{ResponseWriter: w}
+        defer func() {
+            if p := recover(); p != nil {
+                service.Logger(r.Context()).Error("handler panicked", "err", p, "stack", string(debug.Stack()))
+                err := fmt.Errorf("%w: %v", lib.ErrEnginePanic, p)
+                switch {
+                    case !sw.started:
+                        writeError(w, http.StatusInternalServerError, err)
+                    case w.Header().Get("Content-Type") == "text/event-stream":
+                        writeEvent(sw, "error", api.ErrorReply{Error: api.Error{Status: http.StatusInternalServerError, Message: err.Error()}})
+                    default:
+                        // A plain reply cut off partway can't be fixed
+                }
+            }
+        }()
+        handler(sw, r)
+    }
+}
+
+
+// Remembers whether the response has started. Passes flushes through for
+// streaming endpoints.
+type startedWriter struct {
+    http.ResponseWriter
+    started bool
+}
+
+
+func (self *startedWriter) WriteHeader(status int) {
+    self.started = true
+    self.ResponseWriter.WriteHeader(status)
+}
+
+
+func (self *startedWriter) Write(b []byte) (int, error) {
+    self.started = true
+    return self.ResponseWriter.Write(b)
+}
+
+
+func (self *startedWriter) Flush() {
+    if flusher, ok := self.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+
+// Same for gRPC. Goes inside the logging interceptors, so the call is still
+// logged and counted.
+func recoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (reply interface{}, err error) {
+    defer func() {
+        if p := recover(); p != nil {
+            service.Logger(ctx).Error("call panicked", "err", p, "stack", string(debug.Stack()))
+            err = status.Errorf(codes.Internal, "%v: %v", lib.ErrEnginePanic, p)
+        }
+    }()
+    return handler(ctx, req)
+}
+
+
+func recoverStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+    defer func() {
+        if p := recover(); p != nil {
+            service.Logger(stream.Context()).Error("call panicked", "err", p, "stack", string(debug.Stack()))
+            err = status.Errorf(codes.Internal, "%v: %v", lib.ErrEnginePanic, p)
+        }
+    }()
+    return handler(srv, stream)
+}
+
+
+// Stop taking requests and let the ones in flight finish, along with running
+// simulation jobs, all by the same deadline. Anything still going then is cut
+// off.
+func shutdown(httpServer *http.Server, grpcServer *grpc.Server, timeout time.Duration) {
+    notReady.Store(shuttingDown)
+    slog.Info("shutting down", "timeout", timeout.String())
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+    var wg sync.WaitGroup
+    wg.Add(2)
+    go func() {
+        defer wg.Done()
+        err := httpServer.Shutdown(ctx)
+        if err != nil {
+            slog.Warn("cut off HTTP requests", "err", err)
+            httpServer.Close()
+        }
+    }()
+    go func() {
+        defer wg.Done()
+        err := jobs.Shutdown(ctx)
+        if errors.Is(err, context.DeadlineExceeded) {
+            slog.Warn("canceled running jobs", "err", err)
+        }
+    }()
+    if grpcServer != nil {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            stopped := make(chan struct{})
+            go func() {
+                grpcServer.GracefulStop()
+                close(stopped)
+            }()
+            select {
+                case <-stopped:
+                case <-ctx.Done():
+                    slog.Warn("cut off gRPC calls", "err", ctx.Err())
+                    grpcServer.Stop()
+            }
+        }()
+    }
+    wg.Wait()
+    slog.Info("shut down")
+}
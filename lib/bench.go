@@ -0,0 +1,95 @@
+package lib
+
+
+import (
+    "runtime"
+    "sort"
+    "time"
+)
+
+
+// Play a fixed set of seeded hands and time them, so changes to the engine
+// can be compared run against run. The interesting numbers are for the slowest
+// hands, usually Amulet with a pile of bounce lands, where the search blows up.
+
+
+type BenchHand struct {
+    Seed int64          `json:"seed"`
+    Hand []string       `json:"hand"`
+    OnThePlay bool      `json:"onThePlay"`
+    Turn int            `json:"turn"`
+    Millis float64      `json:"millis"`
+}
+
+
+type BenchReport struct {
+    Deck string             `json:"deck"`
+    Search string           `json:"search"`
+    Hands int               `json:"hands"`
+    Successes int           `json:"successes"`
+    TotalMillis float64     `json:"totalMillis"`
+    MeanMillis float64      `json:"meanMillis"`
+    // States expanded, summed over every hand
+    Expanded int            `json:"expanded"`
+    Allocs uint64           `json:"allocs"`
+    AllocBytes uint64       `json:"allocBytes"`
+    Slowest []BenchHand     `json:"slowest"`
+}
+
+
+func Benchmark(deckName string, nHands int, seed int64, maxTurns int, search string) (BenchReport, error) {
+    report := BenchReport{Deck: deckName, Search: search, Hands: nHands}
+    deck, err := LoadDecklist(deckName)
+    if err != nil {
+        return report, err
+    }
+    budget := DefaultBudget()
+    budget.MaxTurns = maxTurns
+    hands := []BenchHand{}
+    before := runtime.MemStats{}
+    runtime.ReadMemStats(&before)
+    start := time.Now()
+    for i := 0; i < nHands; i++ {
+        handSeed := seed + int64(i)
+        shuffled := ShuffledSeed(deck.Main, handSeed)
+        otp := i % 2 == 0
+        handStart := time.Now()
+        game, err := NewGame(deck.Name, shuffled[7:], shuffled[:7], otp, false, budget, OpponentProfile{}, handSeed)
+        if err != nil {
+            return report, err
+        }
+        game, err = game.Run(search)
+        if err != nil {
+            return report, err
+        }
+        report.Expanded += game.stats.Expanded
+        turn := -1
+        if game.success {
+            turn = game.turn
+            report.Successes += 1
+        }
+        hands = append(hands, BenchHand{
+            Seed: handSeed,
+            Hand: shuffled[:7],
+            OnThePlay: otp,
+            Turn: turn,
+            Millis: float64(time.Since(handStart).Microseconds()) / 1000,
+        })
+    }
+    elapsed := time.Since(start)
+    after := runtime.MemStats{}
+    runtime.ReadMemStats(&after)
+    report.TotalMillis = float64(elapsed.Microseconds()) / 1000
+    if nHands > 0 {
+        report.MeanMillis = report.TotalMillis / float64(nHands)
+    }
+    report.Allocs = after.Mallocs - before.Mallocs
+    report.AllocBytes = after.TotalAlloc - before.TotalAlloc
+    sort.Slice(hands, func(i, j int) bool {
+        return hands[i].Millis > hands[j].Millis
+    })
+    for i := 0; i < len(hands) && i < 5; i++ {
+        report.Slowest = append(report.Slowest, hands[i])
+    }
+    return report, nil
+}
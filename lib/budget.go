@@ -0,0 +1,39 @@
+package lib
+
+
+import (
+    "time"
+)
+
+
+// Limits on how hard the search works on one game. The server sets these from
+// its config, and requests can ask for less.
+type Budget struct {
+    // Give up if Titan hasn't been cast by the end of this turn
+    MaxTurns int
+    // Once a game has run this long, remaining lines give up
+    Timeout time.Duration
+    // Stop playing lands once there's this much mana. Titan costs six.
+    ManaCap int
+    // Once this many states have been expanded, remaining lines give up. Zero
+    // means no limit.
+    MaxExpanded int
+}
+
+
+func DefaultBudget() Budget {
+    return Budget{
+        MaxTurns: 4,
+        Timeout: 4 * time.Second,
+        ManaCap: 6,
+        MaxExpanded: 0,
+    }
+}
+
+
+func (self *SearchStats) exhausted(budget *Budget) bool {
+    if self.ctx.Err() != nil {
+        return true
+    }
+    return budget.MaxExpanded > 0 && self.Expanded > budget.MaxExpanded
+}
@@ -3,99 +3,248 @@ package lib
 
 import (
     "errors"
-    "gopkg.in/yaml.v2"
-    "io/ioutil"
-    "log"
+    "fmt"
+    "log/slog"
+    "math"
+    "path"
+    "strings"
+    "sync"
+    "sync/atomic"
 )
 
 
+// Cards are interned to small integer IDs, so zones can be count vectors and
+// card data can be looked up by index rather than by name. IDs are handed out
+// the first time a name is seen and never change, even across card data
+// reloads.
+type cardID uint16
+
+
 type card struct {
-    name string
+    id cardID
+}
+
+
+// Names past the last ID all share it, and it never has card data
+const overflowID = cardID(math.MaxUint16)
+
+
+var ErrTooManyCards = errors.New("too many card names")
+
+
+type cardRegistry struct {
+    names []string
+    ids map[string]cardID
+}
+
+
+// Lookups are lock-free. Interning a new name copies the registry and swaps
+// it in under the lock.
+var registry atomic.Value
+var registryLock sync.Mutex
+
+
+func init() {
+    registry.Store(&cardRegistry{ids: make(map[string]cardID)})
 }
 
 
 func Card(name string) card {
-    return card{name: name}
+    c, _ := internCard(name, name)
+    return c
+}
+
+
+// Same as Card, but says if the name didn't get an ID of its own. The key is
+// usually the name, but a card a deck has its own data for is keyed by deck
+// too, so it gets an ID apart from the shared card of the same name.
+func internCard(key string, name string) (card, error) {
+    if id, ok := registry.Load().(*cardRegistry).ids[key]; ok {
+        return card{id: id}, nil
+    }
+    registryLock.Lock()
+    defer registryLock.Unlock()
+    old := registry.Load().(*cardRegistry)
+    if id, ok := old.ids[key]; ok {
+        return card{id: id}, nil
+    }
+    if len(old.names) >= int(overflowID) {
+        return card{id: overflowID}, fmt.Errorf("%w: %s", ErrTooManyCards, name)
+    }
+    reg := &cardRegistry{
+        names: append(old.names[:len(old.names):len(old.names)], name),
+        ids: make(map[string]cardID, len(old.ids)+1),
+    }
+    for k, v := range old.ids {
+        reg.ids[k] = v
+    }
+    id := cardID(len(old.names))
+    reg.ids[key] = id
+    registry.Store(reg)
+    return card{id: id}, nil
+}
+
+
+// How many card names have been interned so far. Every card ID is less than
+// this.
+func nCardIDs() int {
+    return len(registry.Load().(*cardRegistry).names)
+}
+
+
+func (self *card) Name() string {
+    names := registry.Load().(*cardRegistry).names
+    if int(self.id) < len(names) {
+        return names[self.id]
+    }
+    return ""
+}
+
+
+func (self *card) data() *cardData {
+    byID := cardDataCache().byID
+    if int(self.id) < len(byID) && byID[self.id] != nil {
+        return byID[self.id]
+    }
+    return &cardData{}
 }
 
 
 func (self *card) Pretty() string {
-    return GetCardData(self.name).Pretty
+    return self.data().Pretty
 }
 
 
-func (self *card) ToJSON() string {
-    var t tag
+func (self *card) Tag() PlayTag {
     if self.IsLand() {
-        t = Tag("land", self.Pretty(), self.Target())
-    } else {
-        t = Tag("spell", self.Pretty(), self.Target())
+        return Tag("land", self.Pretty(), self.Target())
     }
-    return t.ToJSON()
+    return Tag("spell", self.Pretty(), self.Target())
 }
 
 
 func (self *card) TapsFor() mana {
-    return GetCardData(self.name).TapsFor
+    return self.data().TapsFor
 }
 
 
 func (self *card) CastingCost() mana {
-    return GetCardData(self.name).CastingCost
+    return self.data().CastingCost
 }
 
 
 func (self *card) CanBeTitan() bool {
     // Most common fail case is that we can't find Primeval Titan. Let's try to
     // identify those situations sooner.
-    return GetCardData(self.name).CanBeTitan
+    return self.data().CanBeTitan
 }
 
 
 func (self *card) AlwaysCast() bool {
-    return GetCardData(self.name).AlwaysCast
+    return self.data().AlwaysCast
+}
+
+
+func (self *card) Type() string {
+    return self.data().Type
 }
 
 
 func (self *card) IsLand() bool {
-    return GetCardData(self.name).Type == "land"
+    return self.data().Type == "land"
+}
+
+
+func (self *card) IsBasic() bool {
+    return self.data().Basic
+}
+
+
+// Some cards stand in for a handful of similar cards, like Simic Growth
+// Chamber for all the bounce lands, so they're exempt from the four-of rule.
+func (self *card) AnyNumber() bool {
+    return self.IsBasic() || self.data().AnyNumber
+}
+
+
+func (self *card) IsSpell() bool {
+    return !self.IsLand() && self.Name() != "Elvish Spirit Guide"
+}
+
+
+// Vanilla cards are in the deck for reasons the model doesn't care about, like
+// sideboard interaction. They resolve with no effect.
+func (self *card) IsVanilla() bool {
+    return self.data().Vanilla
+}
+
+
+// Cards with their own handling in gameState cast, play, and activate. Keep
+// this in sync with the switch statements there.
+var behaviors = map[string]bool{
+    "Abundant Harvest": true,
+    "Adventurous Impulse": true,
+    "Amulet of Vigor": true,
+    "Ancient Stirrings": true,
+    "Arboreal Grazer": true,
+    "Azusa, Lost but Seeking": true,
+    "Bojuka Bog": true,
+    "Castle Garenbrig": true,
+    "Crumbling Vestige": true,
+    "Dryad of the Ilysian Grove": true,
+    "Elvish Spirit Guide": true,
+    "Explore": true,
+    "Force of Vigor": true,
+    "Forest": true,
+    "Primeval Titan": true,
+    "Simic Growth Chamber": true,
+    "Summoner's Pact": true,
+    "Urza's Saga": true,
+    "Urza's Saga (II)": true,
+    "Valakut, the Molten Pinnacle": true,
+    "Wastes": true,
+}
+
+
+func hasBehavior(c card) bool {
+    return behaviors[c.Name()]
 }
 
 
 func (self *card) IsBounceLand() bool {
-    return self.name == "Simic Growth Chamber"
+    return self.Name() == "Simic Growth Chamber"
 }
 
 
 func (self *card) IsCreature() bool {
-    return GetCardData(self.name).Type == "creature"
+    return self.data().Type == "creature"
 }
 
 
 func (self *card) IsColorless() bool {
-    return GetCardData(self.name).Type == "land" || self.name == "Amulet of Vigor"
+    return self.data().Type == "land" || self.Name() == "Amulet of Vigor"
 }
 
 
 func (self *card) HasAbility() bool {
-    return GetCardData(self.name).ActivationCost.Total != 0
+    return self.data().ActivationCost.Total != 0
 }
 
 
 func (self *card) ActivationCost() mana {
-    return GetCardData(self.name).ActivationCost
+    return self.data().ActivationCost
 }
 
 
 func (self *card) EntersTapped() bool {
-    return GetCardData(self.name).EntersTapped
+    return self.data().EntersTapped
 }
 
 
 func (self *card) Target() string {
-    ret := GetCardData(self.name).Target
+    ret := self.data().Target
     if ret == "" {
-        ret = self.name
+        ret = self.Name()
     }
     return ret
 }
@@ -105,59 +254,217 @@ type cardData struct {
     // No need to duplicate card metadata over and over. Cache it by card name
     // and look it up as needed.
     Name string         `yaml:"name"`
-    ActivationCost mana `yaml:"activation_cost"`
-    CastingCost mana    `yaml:"casting_cost"`
-    EntersTapped bool   `yaml:"enters_tapped"`
-    Pretty string       `yaml:"pretty"`
-    Target string       `yaml:"target"`
-    Type string         `yaml:"type"`
-    TapsFor mana        `yaml:"taps_for"`
-    CanBeTitan bool     `yaml:"can_be_titan"`
-    AlwaysCast bool     `yaml:"always_cast"`
+    ActivationCost mana `yaml:"activation_cost,omitempty"`
+    CastingCost mana    `yaml:"casting_cost,omitempty"`
+    EntersTapped bool   `yaml:"enters_tapped,omitempty"`
+    Pretty string       `yaml:"pretty,omitempty"`
+    Target string       `yaml:"target,omitempty"`
+    Type string         `yaml:"type,omitempty"`
+    Basic bool          `yaml:"basic,omitempty"`
+    TapsFor mana        `yaml:"taps_for,omitempty"`
+    CanBeTitan bool     `yaml:"can_be_titan,omitempty"`
+    AlwaysCast bool     `yaml:"always_cast,omitempty"`
+    Vanilla bool        `yaml:"vanilla,omitempty"`
+    AnyNumber bool      `yaml:"any_number,omitempty"`
+    ScryfallID string   `yaml:"scryfall_id,omitempty"`
+    Aliases []string    `yaml:"aliases,omitempty"`
 }
 
 
-// Cache card data by name so we don't re-read the file repeatedly
-var cardCache = make(map[string]cardData)
+// Cache card data by name so we don't re-read the file repeatedly. The whole
+// set is swapped out at once on reload, so a search in progress never sees a
+// half-loaded cache.
+type cardDataSet struct {
+    // From the shared carddata.yaml
+    cards map[string]cardData
+    // Each deck's own card data, on top of the shared file
+    layers map[string]*cardLayer
+    // All of it indexed by card ID, for the engine
+    byID []*cardData
+    // Normalized name to card data name, for ResolveCardName
+    names map[string]string
+}
+
+
+// The card data one deck sees. Cards in the deck's own file get IDs of their
+// own, so they can differ from deck to deck.
+type cardLayer struct {
+    shared map[string]cardData
+    cards map[string]cardData
+    ids map[string]card
+}
+
+
+// A deck with no card data of its own just sees the shared file
+func (self *cardDataSet) layer(deck string) *cardLayer {
+    if layer, ok := self.layers[deck]; ok {
+        return layer
+    }
+    return &cardLayer{shared: self.cards}
+}
+
+
+func (self *cardLayer) card(name string) card {
+    if c, ok := self.ids[name]; ok {
+        return c
+    }
+    return Card(name)
+}
+
+
+func (self *cardLayer) lookup(name string) (cardData, bool) {
+    if cd, ok := self.cards[name]; ok {
+        return cd, true
+    }
+    cd, ok := self.shared[name]
+    return cd, ok
+}
+
+
+func layerKey(deck string, name string) string {
+    return deck + "\x00" + name
+}
+
+
+var cardCache atomic.Value
+
+
+func cardDataCache() *cardDataSet {
+    cds, ok := cardCache.Load().(*cardDataSet)
+    if !ok {
+        // With bad data, every card is unknown until a reload fixes it
+        err := ReloadCardData()
+        if err != nil {
+            slog.Error("no card data", "err", err)
+            cardCache.CompareAndSwap(nil, &cardDataSet{})
+        }
+        cds = cardCache.Load().(*cardDataSet)
+    }
+    return cds
+}
+
+
+// Re-read all card data. If anything is wrong, keep the old data.
+func ReloadCardData() error {
+    cds, problems := loadCardDataSet()
+    if len(problems) > 0 {
+        return problems
+    }
+    cardCache.Store(cds)
+    return nil
+}
 
 
-func InitCardDataCache() {
-    cardDataRaw := []cardData{}
-    textBytes, err := ioutil.ReadFile("carddata.yaml")
+func loadCardDataSet() (*cardDataSet, CardDataErrors) {
+    cds := &cardDataSet{cards: make(map[string]cardData), layers: make(map[string]*cardLayer)}
+    sources := make(map[string]cardSource)
+    problems := loadCardData(cds.cards, "carddata.yaml", sources)
+    problems = append(problems, checkCardTargets(cds.cards, sources)...)
+    problems = append(problems, internCards(cds.cards, sources, func(name string) string { return name })...)
+    // Per-deck card data is layered on top of the shared file. Each deck only
+    // sees its own.
+    deckFiles, err := listDataDir(deckDir)
     if err != nil {
-        log.Fatal(err)
+        problems = append(problems, &CardDataError{File: deckDir, Message: err.Error()})
+    }
+    all := make(map[string]cardData)
+    for name, cd := range cds.cards {
+        all[name] = cd
+    }
+    for _, filename := range deckFiles {
+        if path.Ext(filename) != ".yaml" {
+            continue
+        }
+        deck := strings.TrimSuffix(filename, ".yaml")
+        layer := &cardLayer{shared: cds.cards, cards: make(map[string]cardData), ids: make(map[string]card)}
+        deckSources := make(map[string]cardSource)
+        problems = append(problems, loadCardData(layer.cards, path.Join(deckDir, filename), deckSources)...)
+        visible := make(map[string]cardData)
+        for name, cd := range cds.cards {
+            visible[name] = cd
+        }
+        for name, cd := range layer.cards {
+            visible[name] = cd
+            all[name] = cd
+        }
+        problems = append(problems, checkCardTargets(visible, deckSources)...)
+        key := func(name string) string { return layerKey(deck, name) }
+        problems = append(problems, internCards(layer.cards, deckSources, key)...)
+        for name, _ := range layer.cards {
+            layer.ids[name] = layerCard(deck, name)
+        }
+        cds.layers[deck] = layer
+    }
+    cds.names = buildNameIndex(all)
+    cds.byID = make([]*cardData, nCardIDs())
+    for name, _ := range cds.cards {
+        cd := cds.cards[name]
+        if c := Card(name); c.id != overflowID {
+            cds.byID[c.id] = &cd
+        }
+    }
+    for _, layer := range cds.layers {
+        for name, _ := range layer.cards {
+            cd := layer.cards[name]
+            if c := layer.ids[name]; c.id != overflowID {
+                cds.byID[c.id] = &cd
+            }
+        }
     }
-    err = yaml.Unmarshal(textBytes, &cardDataRaw)
+    return cds, problems
+}
+
+
+// Intern everything up front so every card with data has a slot
+func internCards(cards map[string]cardData, sources map[string]cardSource, key func(string) string) CardDataErrors {
+    problems := CardDataErrors{}
+    for name, _ := range cards {
+        _, err := internCard(key(name), name)
+        if err != nil {
+            src := sources[name]
+            problems = append(problems, &CardDataError{File: src.file, Line: src.line, Message: err.Error()})
+        }
+    }
+    return problems
+}
+
+
+func layerCard(deck string, name string) card {
+    c, _ := internCard(layerKey(deck, name), name)
+    return c
+}
+
+
+func loadCardData(cards map[string]cardData, filename string, sources map[string]cardSource) CardDataErrors {
+    textBytes, err := readDataFile(filename)
     if err != nil {
-        log.Fatal(err)
+        return CardDataErrors{&CardDataError{File: filename, Message: err.Error()}}
     }
-    log.Println("loading carddata.yaml")
+    cardDataRaw, problems := decodeCardData(filename, textBytes, sources)
+    slog.Info("loading card data", "file", filename)
     for _, cd := range cardDataRaw {
         // Pre-compute this since it gets used a lot
         if cd.Pretty == "" {
             cd.Pretty = slug(cd.Name)
         }
-        cardCache[cd.Name] = cd
+        cards[cd.Name] = cd
     }
+    return problems
 }
 
 
 func GetCardData(cardName string) cardData {
-    if len(cardCache) == 0 {
-        InitCardDataCache()
-    }
-    return cardCache[cardName]
+    return cardDataCache().cards[cardName]
 }
 
 
-func EnsureCardData(cardNames []string) error {
-    if len(cardCache) == 0 {
-        InitCardDataCache()
-    }
+// Make sure the deck has data for every card, its own or shared
+func EnsureCardData(deck string, cardNames []string) error {
+    layer := cardDataCache().layer(deck)
     for _, cardName := range cardNames {
-        _, ok := cardCache[cardName]
+        _, ok := layer.lookup(cardName)
         if !ok {
-            return errors.New("no data for: " + cardName)
+            return &UnknownCardError{Name: cardName, Suggestions: suggestCardNames(cardName)}
         }
     }
     return nil
@@ -2,13 +2,18 @@ package lib
 
 
 import (
-    "log"
+    "errors"
+    "fmt"
     "strings"
 )
 
 
+// Cards are only ever split off the top of the library
+var ErrLibraryExhausted = errors.New("not enough cards in library")
+
+
 type cardArray struct {
-    // An ordered sequence of card names, such as a library
+    // An ordered sequence of cards, such as a library
     arr []card
 }
 
@@ -18,7 +23,8 @@ func CardArray(arr []card) cardArray {
 }
 
 
-func (self *cardArray) Items() map[card]int {
+// Distinct cards in the array, in ID order
+func (self *cardArray) Items() []card {
     cm := CardMap(self.arr)
     return cm.Items()
 }
@@ -38,11 +44,29 @@ func (self *cardArray) Pretty() string {
 }
 
 
-func (ca cardArray) SplitAfter(n int) ([]card, cardArray) {
+func (self *cardArray) Equals(other cardArray) bool {
+    if len(self.arr) != len(other.arr) {
+        return false
+    }
+    for i, c := range self.arr {
+        if other.arr[i] != c {
+            return false
+        }
+    }
+    return true
+}
+
+
+func (self *cardArray) Size() int {
+    return len(self.arr)
+}
+
+
+func (ca cardArray) SplitAfter(n int) ([]card, cardArray, error) {
     if len(ca.arr) < n {
-        log.Fatal("attempted SplitAfter larger than the cardArray")
+        return nil, ca, fmt.Errorf("%w: need %d, have %d", ErrLibraryExhausted, n, len(ca.arr))
     }
     popped := ca.arr[:n]
     ca.arr = ca.arr[n:]
-    return popped, ca
+    return popped, ca, nil
 }
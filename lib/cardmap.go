@@ -2,7 +2,9 @@ package lib
 
 
 import (
-    "log"
+    "errors"
+    "fmt"
+    "math"
     "sort"
     "strconv"
     "strings"
@@ -10,28 +12,33 @@ import (
 
 
 type cardMap struct {
-    // A non-ordered container of cards, such as a hand or battlefield
-    counts map[card]int
+    // A non-ordered container of cards, such as a hand or battlefield. Counts
+    // are indexed by card ID. Trailing IDs that were never added are left off,
+    // so the vector may be shorter than the number of interned cards.
+    counts []uint8
+    // Set by the first Plus or Minus that couldn't be done, and carried
+    // along from there. Check it with Err once the changes are made.
+    err error
 }
 
 
+var (
+    ErrTooManyCopies = errors.New("too many copies")
+    ErrMissingCard = errors.New("card isn't there to take")
+)
+
+
 func CardMap(cards []card) cardMap {
-    counts := make(map[card]int)
-    for _, c := range cards {
-        counts[c] += 1
-    }
-    return cardMap{counts: counts}
+    cm := cardMap{}
+    return cm.Plus(cards...)
 }
 
 
 func (self *cardMap) Pretty() string {
     chunks := []string{}
-    for c, n := range self.counts {
-        if n == 0 {
-            continue
-        }
+    for _, c := range self.Items() {
         chunk := c.Pretty()
-        if n > 1 {
+        if n := self.Count(c); n > 1 {
             chunk += "*" + strconv.Itoa(n)
         }
         chunks = append(chunks, chunk)
@@ -41,57 +48,142 @@ func (self *cardMap) Pretty() string {
 }
 
 
-func (self *cardMap) Items() map[card]int {
-    // TODO: iterate over keys in alphabetical order
-    return self.counts
+// Distinct cards present, in ID order. Use Count for how many of each.
+func (self *cardMap) Items() []card {
+    cards := []card{}
+    for id, n := range self.counts {
+        if n > 0 {
+            cards = append(cards, card{id: cardID(id)})
+        }
+    }
+    return cards
+}
+
+
+// Expand back into a list, one entry per copy
+func (self *cardMap) Cards() []card {
+    cards := []card{}
+    for id, n := range self.counts {
+        for i := 0; i < int(n); i++ {
+            cards = append(cards, card{id: cardID(id)})
+        }
+    }
+    return cards
+}
+
+
+func (self *cardMap) Names() map[string]int {
+    names := make(map[string]int)
+    for _, c := range self.Items() {
+        names[c.Name()] = self.Count(c)
+    }
+    return names
+}
+
+
+func (self *cardMap) Size() int {
+    size := 0
+    for _, n := range self.counts {
+        size += int(n)
+    }
+    return size
 }
 
 
 func (self *cardMap) Count(c card) int {
-    return self.counts[c]
+    if int(c.id) >= len(self.counts) {
+        return 0
+    }
+    return int(self.counts[c.id])
+}
+
+
+// True if every card in other is here at least as many times
+func (self *cardMap) Contains(other cardMap) bool {
+    for id, n := range other.counts {
+        if n > 0 && self.Count(card{id: cardID(id)}) < int(n) {
+            return false
+        }
+    }
+    return true
+}
+
+
+func (self *cardMap) Equals(other cardMap) bool {
+    return self.Contains(other) && other.Contains(*self)
+}
+
+
+// Copy the counts, with room for the given card
+func (self *cardMap) grow(c card) []uint8 {
+    n := len(self.counts)
+    if int(c.id) >= n {
+        n = int(c.id) + 1
+    }
+    counts := make([]uint8, n)
+    copy(counts, self.counts)
+    return counts
 }
 
 
 func (self *cardMap) Replace(c0 card, c1 card) cardMap {
-    counts := make(map[card]int)
-    for c, n := range self.counts {
-        if c == c0 {
-            counts[c1] += n
-        } else {
-            counts[c] += n
+    counts := self.grow(c1)
+    if int(c0.id) < len(self.counts) {
+        if int(counts[c1.id]) + int(counts[c0.id]) > math.MaxUint8 {
+            return cardMap{counts: self.counts, err: self.tooMany(c1)}
         }
+        counts[c1.id] += counts[c0.id]
+        counts[c0.id] = 0
     }
-    return cardMap{counts: counts}
+    return cardMap{counts: counts, err: self.err}
 }
 
 
 func (self *cardMap) Plus(cards ...card) cardMap {
-    counts := make(map[card]int)
-    for c, n := range self.counts {
-        counts[c] = n
-    }
+    counts := append([]uint8{}, self.counts...)
     for _, c := range cards {
-        counts[c] += 1
+        for int(c.id) >= len(counts) {
+            counts = append(counts, 0)
+        }
+        if counts[c.id] == math.MaxUint8 {
+            return cardMap{counts: self.counts, err: self.tooMany(c)}
+        }
+        counts[c.id] += 1
     }
-    return cardMap{counts: counts}
+    return cardMap{counts: counts, err: self.err}
 }
 
 
 func (self *cardMap) Minus(cards ...card) cardMap {
-    counts := make(map[card]int)
-    for c, n := range self.counts {
-        counts[c] = n
-    }
+    counts := append([]uint8{}, self.counts...)
     for _, c := range cards {
-        if counts[c] > 0 {
-            counts[c] -= 1
+        if int(c.id) < len(counts) && counts[c.id] > 0 {
+            counts[c.id] -= 1
         } else {
-            log.Fatal("can't pop ", c, " from ", counts)
-        }
-        // Don't leave keys sitting around with zero value
-        if counts[c] == 0 {
-            delete(counts, c)
+            return cardMap{counts: self.counts, err: self.missing(c)}
         }
     }
-    return cardMap{counts: counts}
+    return cardMap{counts: counts, err: self.err}
+}
+
+
+// Keep the first error, since later ones probably follow from it
+func (self *cardMap) tooMany(c card) error {
+    if self.err != nil {
+        return self.err
+    }
+    return fmt.Errorf("%w: %s, max is %d", ErrTooManyCopies, c.Name(), math.MaxUint8)
+}
+
+
+func (self *cardMap) missing(c card) error {
+    if self.err != nil {
+        return self.err
+    }
+    return fmt.Errorf("%w: %s from %s", ErrMissingCard, c.Name(), self.Pretty())
+}
+
+
+func (self *cardMap) Err() error {
+    return self.err
 }
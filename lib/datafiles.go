@@ -0,0 +1,120 @@
+package lib
+
+
+import (
+    "io/fs"
+    "io/ioutil"
+    "log/slog"
+    "maps"
+    "os"
+    "path"
+    "path/filepath"
+    "sort"
+    "time"
+)
+
+
+// Data files (carddata.yaml, opponents.yaml, and the decks directory) are
+// looked up in the data directory first, then in the defaults compiled into
+// the binary. That way the server runs from anywhere, and any file can be
+// overridden on disk without a rebuild. Paths here are slash-separated and
+// relative to the data directory.
+
+
+var defaultData fs.FS = os.DirFS(".")
+var dataDir = "."
+
+
+func SetDataFiles(defaults fs.FS, dir string) {
+    defaultData = defaults
+    dataDir = dir
+}
+
+
+func readDataFile(name string) ([]byte, error) {
+    textBytes, err := ioutil.ReadFile(filepath.Join(dataDir, filepath.FromSlash(name)))
+    if err == nil || !os.IsNotExist(err) {
+        return textBytes, err
+    }
+    return fs.ReadFile(defaultData, name)
+}
+
+
+// Names of the files in a data subdirectory, from disk and defaults combined
+func listDataDir(dir string) ([]string, error) {
+    seen := make(map[string]bool)
+    files, err := ioutil.ReadDir(filepath.Join(dataDir, filepath.FromSlash(dir)))
+    if err != nil && !os.IsNotExist(err) {
+        return []string{}, err
+    }
+    for _, f := range files {
+        if !f.IsDir() {
+            seen[f.Name()] = true
+        }
+    }
+    entries, err := fs.ReadDir(defaultData, dir)
+    if err != nil && !os.IsNotExist(err) {
+        return []string{}, err
+    }
+    for _, e := range entries {
+        if !e.IsDir() {
+            seen[e.Name()] = true
+        }
+    }
+    names := []string{}
+    for name, _ := range seen {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names, nil
+}
+
+
+// New files, like uploaded decks, always go on disk
+func writeDataFile(name string, data []byte) error {
+    filename := filepath.Join(dataDir, filepath.FromSlash(name))
+    err := os.MkdirAll(filepath.Dir(filename), 0755)
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(filename, data, 0644)
+}
+
+
+// Poll the data directory and reload card data when anything in it changes,
+// including files being added or deleted. Decklists and opponents are read
+// fresh on every request, so they don't need any help. Runs until the
+// process exits.
+func WatchDataFiles(interval time.Duration) {
+    last := dataFilesModTimes()
+    for range time.Tick(interval) {
+        latest := dataFilesModTimes()
+        if maps.Equal(latest, last) {
+            continue
+        }
+        last = latest
+        err := ReloadCardData()
+        if err != nil {
+            slog.Error("not reloading card data", "err", err)
+        }
+    }
+}
+
+
+// Modification times of the data files on disk. Files only in the defaults
+// never change, so they're left out.
+func dataFilesModTimes() map[string]time.Time {
+    modTimes := make(map[string]time.Time)
+    names := []string{"carddata.yaml", "opponents.yaml"}
+    deckFiles, _ := listDataDir(deckDir)
+    for _, name := range deckFiles {
+        names = append(names, path.Join(deckDir, name))
+    }
+    for _, name := range names {
+        info, err := os.Stat(filepath.Join(dataDir, filepath.FromSlash(name)))
+        if err == nil {
+            modTimes[name] = info.ModTime()
+        }
+    }
+    return modTimes
+}
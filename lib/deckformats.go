@@ -0,0 +1,175 @@
+package lib
+
+
+import (
+    "encoding/xml"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+
+// Decklists show up in a few different formats. They all get normalized to
+// the same Decklist.
+//
+// - "text" is our own format: "N Card Name" lines with an optional
+//   "Sideboard" header. Blank lines and # comments are ignored.
+// - "arena" is the MTG Arena export: "4 Forest (DMU) 277" lines under "Deck"
+//   and "Sideboard" headers, maybe with an "About" section up top.
+// - "goldfish" is the MTGGoldfish plain text download: "N Card Name" lines
+//   with a blank line between the main deck and sideboard.
+// - "mtgo" is the MTGO .dek XML file.
+var DeckFormats = []string{"text", "arena", "goldfish", "mtgo"}
+
+
+// Matches "4 Card Name", "4x Card Name", and "4 Card Name (SET) 123"
+var deckLinePattern = regexp.MustCompile(`^(\d+)x?\s+(.+?)(?:\s+\([A-Z0-9]+\)\s+\S+)?$`)
+
+
+// Guess the format of a decklist from its contents. We can't tell goldfish
+// apart from our own format, since ours allows blank lines, so goldfish has to
+// be asked for by name.
+func DetectDeckFormat(text string) string {
+    trimmed := strings.TrimSpace(text)
+    if strings.HasPrefix(trimmed, "<?xml") || strings.HasPrefix(trimmed, "<Deck") {
+        return "mtgo"
+    }
+    for _, line := range strings.Split(trimmed, "\n") {
+        line = strings.TrimSpace(line)
+        if line == "Deck" || line == "About" {
+            return "arena"
+        }
+    }
+    return "text"
+}
+
+
+// Parse a decklist in the given format, or guess the format if it's empty.
+// Malformed lines are reported as problems, with line numbers, rather than
+// failing outright.
+func ParseDecklistAs(text string, format string) (Decklist, []string) {
+    if format == "" {
+        format = DetectDeckFormat(text)
+    }
+    switch format {
+        case "text", "arena", "goldfish":
+            return parseDeckText(text, format)
+        case "mtgo":
+            return parseDeckMTGO(text)
+    }
+    return Decklist{}, []string{"unknown decklist format: " + format}
+}
+
+
+func parseDeckText(text string, format string) (Decklist, []string) {
+    deck := Decklist{}
+    problems := []string{}
+    sideboard := false
+    // Arena puts the deck name and such in an "About" section
+    about := false
+    for i, line := range strings.Split(text, "\n") {
+        line = strings.TrimSpace(line)
+        if len(line) == 0 {
+            about = false
+            // Goldfish marks the sideboard with a blank line
+            if format == "goldfish" && len(deck.Main) > 0 {
+                sideboard = true
+            }
+            continue
+        }
+        if line[:1] == "#" {
+            continue
+        }
+        switch strings.TrimSuffix(strings.ToLower(line), ":") {
+            case "sideboard", "companion":
+                sideboard = true
+                continue
+            case "deck":
+                sideboard = false
+                continue
+            case "about":
+                about = true
+                continue
+        }
+        if about {
+            continue
+        }
+        match := deckLinePattern.FindStringSubmatch(line)
+        n := 0
+        if match != nil {
+            n, _ = strconv.Atoi(match[1])
+        }
+        if n <= 0 {
+            lineNumber := "line " + strconv.Itoa(i+1) + ": "
+            problems = append(problems, lineNumber + "expected \"N Card Name\", got \"" + line + "\"")
+            continue
+        }
+        for j := 0; j < n; j++ {
+            if sideboard {
+                deck.Sideboard = append(deck.Sideboard, match[2])
+            } else {
+                deck.Main = append(deck.Main, match[2])
+            }
+        }
+    }
+    return deck, problems
+}
+
+
+type mtgoDeck struct {
+    Cards []struct {
+        Quantity int        `xml:"Quantity,attr"`
+        Sideboard bool      `xml:"Sideboard,attr"`
+        Name string         `xml:"Name,attr"`
+    }                       `xml:"Cards"`
+}
+
+
+func parseDeckMTGO(text string) (Decklist, []string) {
+    deck := Decklist{}
+    raw := mtgoDeck{}
+    err := xml.Unmarshal([]byte(text), &raw)
+    if err != nil {
+        return deck, []string{"bad .dek file: " + err.Error()}
+    }
+    problems := []string{}
+    for _, c := range raw.Cards {
+        if c.Quantity <= 0 || c.Name == "" {
+            problems = append(problems, "bad .dek entry: " + strconv.Itoa(c.Quantity) + " \"" + c.Name + "\"")
+            continue
+        }
+        for j := 0; j < c.Quantity; j++ {
+            if c.Sideboard {
+                deck.Sideboard = append(deck.Sideboard, c.Name)
+            } else {
+                deck.Main = append(deck.Main, c.Name)
+            }
+        }
+    }
+    return deck, problems
+}
+
+
+// Write a decklist back out in our own text format
+func (self *Decklist) ToText() string {
+    ret := deckSectionText(self.Main)
+    if len(self.Sideboard) > 0 {
+        ret += "\nSideboard\n" + deckSectionText(self.Sideboard)
+    }
+    return ret
+}
+
+
+func deckSectionText(names []string) string {
+    counts := countNames(names)
+    ret := ""
+    // Names come in with repeats. Only write each once, in order
+    seen := make(map[string]bool)
+    for _, name := range names {
+        if !seen[name] {
+            ret += strconv.Itoa(counts[name]) + " " + name + "\n"
+        }
+        seen[name] = true
+    }
+    return ret
+}
@@ -0,0 +1,136 @@
+package lib
+
+
+import (
+    "maps"
+    "testing"
+)
+
+
+func TestParseDecklistAs(t *testing.T) {
+    cases := []struct {
+        name string
+        text string
+        format string
+        main map[string]int
+        side map[string]int
+        problems int
+    }{
+        {
+            name: "text",
+            text: "# Titan\n4 Primeval Titan\n2x Forest\n\nSideboard\n1 Thragtusk\n",
+            main: map[string]int{"Primeval Titan": 4, "Forest": 2},
+            side: map[string]int{"Thragtusk": 1},
+        },
+        {
+            name: "text with a sideboard colon",
+            text: "4 Primeval Titan\nSideboard:\n2 Dismember\n",
+            main: map[string]int{"Primeval Titan": 4},
+            side: map[string]int{"Dismember": 2},
+        },
+        {
+            name: "text ignores blank lines",
+            text: "4 Primeval Titan\n\n2 Forest\n",
+            main: map[string]int{"Primeval Titan": 4, "Forest": 2},
+            side: map[string]int{},
+        },
+        {
+            name: "arena",
+            text: "About\nName Amulet Titan\n\nDeck\n4 Primeval Titan (M19) 180\n6 Forest (DMU) 277\n\nSideboard\n2 Dismember (MH1) 85\n",
+            main: map[string]int{"Primeval Titan": 4, "Forest": 6},
+            side: map[string]int{"Dismember": 2},
+        },
+        {
+            name: "arena companion",
+            text: "Companion\n1 Obstinate Baloth (M11) 188\n\nDeck\n4 Primeval Titan (M19) 180\n",
+            main: map[string]int{"Primeval Titan": 4},
+            side: map[string]int{"Obstinate Baloth": 1},
+        },
+        {
+            name: "goldfish",
+            text: "4 Primeval Titan\n6 Forest\n\n2 Dismember\n",
+            format: "goldfish",
+            main: map[string]int{"Primeval Titan": 4, "Forest": 6},
+            side: map[string]int{"Dismember": 2},
+        },
+        {
+            name: "goldfish leading blank line",
+            text: "\n4 Primeval Titan\n\n2 Dismember\n",
+            format: "goldfish",
+            main: map[string]int{"Primeval Titan": 4},
+            side: map[string]int{"Dismember": 2},
+        },
+        {
+            name: "mtgo",
+            text: `<?xml version="1.0" encoding="utf-8"?>
+<Deck>
+  <Cards CatID="1" Quantity="4" Sideboard="false" Name="Primeval Titan" />
+  <Cards CatID="2" Quantity="2" Sideboard="true" Name="Dismember" />
+</Deck>`,
+            main: map[string]int{"Primeval Titan": 4},
+            side: map[string]int{"Dismember": 2},
+        },
+        {
+            name: "mtgo bad entry",
+            text: `<Deck><Cards Quantity="0" Sideboard="false" Name="Forest" /></Deck>`,
+            main: map[string]int{},
+            side: map[string]int{},
+            problems: 1,
+        },
+        {
+            name: "malformed counts",
+            text: "4 Primeval Titan\nForest\n0 Forest\nx Forest\n-1 Forest\n",
+            main: map[string]int{"Primeval Titan": 4},
+            side: map[string]int{},
+            problems: 4,
+        },
+        {
+            name: "unknown format",
+            text: "4 Primeval Titan\n",
+            format: "cockatrice",
+            main: map[string]int{},
+            side: map[string]int{},
+            problems: 1,
+        },
+    }
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            deck, problems := ParseDecklistAs(tc.text, tc.format)
+            if len(problems) != tc.problems {
+                t.Errorf("got problems %q, want %d of them", problems, tc.problems)
+            }
+            if got := countNames(deck.Main); !maps.Equal(got, tc.main) {
+                t.Errorf("main: got %v, want %v", got, tc.main)
+            }
+            if got := countNames(deck.Sideboard); !maps.Equal(got, tc.side) {
+                t.Errorf("sideboard: got %v, want %v", got, tc.side)
+            }
+        })
+    }
+}
+
+
+func TestDetectDeckFormat(t *testing.T) {
+    cases := map[string]string{
+        "4 Primeval Titan\n": "text",
+        "Deck\n4 Primeval Titan (M19) 180\n": "arena",
+        "About\nName Titan\n": "arena",
+        "<?xml version=\"1.0\"?>\n<Deck></Deck>": "mtgo",
+        "  <Deck></Deck>": "mtgo",
+    }
+    for text, want := range cases {
+        if got := DetectDeckFormat(text); got != want {
+            t.Errorf("%q: got %s, want %s", text, got, want)
+        }
+    }
+}
+
+
+// Problems point at the line they're on
+func TestParseDecklistLineNumbers(t *testing.T) {
+    _, problems := ParseDecklistAs("4 Primeval Titan\n\nnot a card line\n", "text")
+    want := `line 3: expected "N Card Name", got "not a card line"`
+    if len(problems) != 1 || problems[0] != want {
+        t.Errorf("got %q, want %q", problems, want)
+    }
+}
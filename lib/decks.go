@@ -0,0 +1,341 @@
+package lib
+
+
+import (
+    crand "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io/fs"
+    "math"
+    "path"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+
+// Each deck is a decklist named decks/<name>.txt (or an MTGO export named
+// decks/<name>.dek), optionally alongside card data in decks/<name>.yaml. Per-deck card data is layered on top of the
+// shared carddata.yaml, and only that deck's games see it.
+const deckDir = "decks"
+
+
+const DefaultDeck = "amulet-titan"
+
+
+// Constructed minimum for the main deck
+const minDeckSize = 60
+
+
+var ErrUnknownDeck = errors.New("no such deck")
+var ErrBadPlan = errors.New("bad sideboard plan")
+var ErrDeckTooSmall = errors.New("main deck is too small")
+
+
+func ListDecks() ([]string, error) {
+    names := []string{}
+    files, err := listDataDir(deckDir)
+    if err != nil {
+        return names, err
+    }
+    for _, filename := range files {
+        ext := path.Ext(filename)
+        if ext != ".txt" && ext != ".dek" {
+            continue
+        }
+        name := strings.TrimSuffix(filename, ext)
+        // Don't list a deck twice if it has both
+        if len(names) == 0 || names[len(names)-1] != name {
+            names = append(names, name)
+        }
+    }
+    return names, nil
+}
+
+
+// A decklist as written in the decks directory. Card names are repeated once
+// per copy, in the order they appear in the file.
+type Decklist struct {
+    Name        string      `json:"name"`
+    Main        []string    `json:"main"`
+    Sideboard   []string    `json:"sideboard"`
+}
+
+
+// Cards to bring in from the sideboard and take out of the main deck, by name
+type SideboardPlan struct {
+    In  map[string]int  `json:"in"`
+    Out map[string]int  `json:"out"`
+}
+
+
+func LoadDeck(name string) ([]string, error) {
+    deck, err := LoadDecklist(name)
+    if err != nil {
+        return []string{}, err
+    }
+    return Shuffled(deck.Main), nil
+}
+
+
+// Load a decklist from the registry by name. An empty name gets the default.
+func LoadDecklist(name string) (Decklist, error) {
+    if name == "" {
+        name = DefaultDeck
+    }
+    names, err := ListDecks()
+    if err != nil {
+        return Decklist{}, err
+    }
+    // Only read files we know are there, and don't let the name wander out of
+    // the decks directory
+    known := false
+    for _, n := range names {
+        known = known || n == name
+    }
+    if !known {
+        return Decklist{}, fmt.Errorf("%w: %s", ErrUnknownDeck, name)
+    }
+    deck, problems, err := readDecklist(name)
+    if err != nil {
+        return Decklist{}, err
+    }
+    if len(problems) > 0 {
+        return Decklist{}, errors.New(name + ": " + problems[0])
+    }
+    deck, problems = resolveDecklist(deck)
+    if len(problems) > 0 {
+        return Decklist{}, errors.New(name + ": " + problems[0])
+    }
+    // Files in the data directory aren't checked like uploads are, and
+    // dealing from a short deck would run off the end
+    if len(deck.Main) < minDeckSize {
+        return Decklist{}, fmt.Errorf("%w: %s has %d cards, need at least %d", ErrDeckTooSmall, name, len(deck.Main), minDeckSize)
+    }
+    deck.Name = name
+    return deck, nil
+}
+
+
+// Read and parse a decklist file, leaving the card names as they were typed
+func readDecklist(name string) (Decklist, []string, error) {
+    textBytes, err := readDataFile(path.Join(deckDir, name + ".txt"))
+    if errors.Is(err, fs.ErrNotExist) {
+        textBytes, err = readDataFile(path.Join(deckDir, name + ".dek"))
+    }
+    if err != nil {
+        return Decklist{}, nil, err
+    }
+    deck, problems := ParseDecklist(string(textBytes))
+    return deck, problems, nil
+}
+
+
+// Swap in card data names for whatever was typed. Names that don't resolve
+// are left alone and reported, with suggestions.
+func resolveDecklist(deck Decklist) (Decklist, []string) {
+    problems := []string{}
+    reported := make(map[string]bool)
+    resolve := func(names []string) []string {
+        ret := []string{}
+        for _, name := range names {
+            resolved, err := ResolveCardName(name)
+            if err != nil {
+                if !reported[name] {
+                    problems = append(problems, err.Error())
+                }
+                reported[name] = true
+                resolved = name
+            }
+            ret = append(ret, resolved)
+        }
+        return ret
+    }
+    return Decklist{
+        Name: deck.Name,
+        Main: resolve(deck.Main),
+        Sideboard: resolve(deck.Sideboard),
+    }, problems
+}
+
+
+// Parse a decklist, guessing at the format
+func ParseDecklist(text string) (Decklist, []string) {
+    return ParseDecklistAs(text, "")
+}
+
+
+// Check a decklist for constructed legality and for cards the model doesn't
+// know how to play. Returns a list of problems, empty if the deck is fine.
+func ValidateDecklist(deck Decklist) []string {
+    problems := []string{}
+    if len(deck.Main) < minDeckSize {
+        problems = append(problems, "main deck has " + strconv.Itoa(len(deck.Main)) + " cards, need at least " + strconv.Itoa(minDeckSize))
+    }
+    if len(deck.Sideboard) > 15 {
+        problems = append(problems, "sideboard has " + strconv.Itoa(len(deck.Sideboard)) + " cards, max is 15")
+    }
+    deck, unknown := resolveDecklist(deck)
+    problems = append(problems, unknown...)
+    // Counted by name rather than in a cardMap. Names that don't resolve
+    // shouldn't take up card IDs, which are never given back.
+    counts := countNames(append(append([]string{}, deck.Main...), deck.Sideboard...))
+    cards := cardDataCache().layer(deck.Name)
+    for _, name := range sortedNames(counts) {
+        // Already reported above
+        if _, ok := cards.lookup(name); !ok {
+            continue
+        }
+        c := cards.card(name)
+        n := counts[name]
+        if n > 4 && !c.AnyNumber() {
+            problems = append(problems, strconv.Itoa(n) + " copies of " + name + ", max is 4")
+        } else if n > math.MaxUint8 {
+            // The most a cardMap can hold
+            problems = append(problems, strconv.Itoa(n) + " copies of " + name + ", max is " + strconv.Itoa(math.MaxUint8))
+        }
+        if !c.IsVanilla() && !hasBehavior(c) {
+            problems = append(problems, "no behavior for: " + name + " (mark it vanilla if it doesn't matter)")
+        }
+    }
+    return problems
+}
+
+
+// Validate a decklist and, if it's good, add it to the registry under a new
+// name. Any format is accepted, but it's saved in our own. Problems with the
+// deck are returned alongside an empty name; err is for trouble on our end.
+func SaveDecklist(text string, format string) (string, []string, error) {
+    deck, problems := ParseDecklistAs(text, format)
+    problems = append(problems, ValidateDecklist(deck)...)
+    if len(problems) > 0 {
+        return "", problems, nil
+    }
+    deck, _ = resolveDecklist(deck)
+    b := make([]byte, 4)
+    _, err := crand.Read(b)
+    if err != nil {
+        return "", problems, err
+    }
+    name := "upload-" + hex.EncodeToString(b)
+    err = writeDataFile(path.Join(deckDir, name + ".txt"), []byte(deck.ToText()))
+    if err != nil {
+        return "", problems, err
+    }
+    return name, problems, nil
+}
+
+
+// Swap cards between the main deck and sideboard. The original decklist is
+// left untouched.
+func (self *Decklist) WithPlan(plan SideboardPlan) (Decklist, error) {
+    plan, err := plan.resolved()
+    if err != nil {
+        return Decklist{}, err
+    }
+    main := countNames(self.Main)
+    side := countNames(self.Sideboard)
+    for name, n := range plan.Out {
+        if main[name] < n {
+            return Decklist{}, errors.New("not enough copies to take out: " + name)
+        }
+        main[name] -= n
+        side[name] += n
+    }
+    for name, n := range plan.In {
+        if side[name] < n {
+            return Decklist{}, errors.New("not enough copies in sideboard: " + name)
+        }
+        side[name] -= n
+        main[name] += n
+    }
+    cards := cardDataCache().layer(self.Name)
+    for _, zone := range []map[string]int{main, side} {
+        for name, n := range zone {
+            if n < 0 {
+                return Decklist{}, fmt.Errorf("%w: %d copies of %s", ErrBadPlan, n, name)
+            }
+            if _, ok := cards.lookup(name); !ok || n <= 4 {
+                continue
+            }
+            if c := cards.card(name); !c.AnyNumber() {
+                return Decklist{}, fmt.Errorf("%w: %d copies of %s, max is 4", ErrBadPlan, n, name)
+            }
+        }
+    }
+    ret := Decklist{
+        Name: self.Name,
+        Main: namesFromCounts(main),
+        Sideboard: namesFromCounts(side),
+    }
+    if len(ret.Main) < minDeckSize {
+        return Decklist{}, fmt.Errorf("%w: would have fewer than %d cards", ErrDeckTooSmall, minDeckSize)
+    }
+    err = EnsureCardData(self.Name, sortedNames(main))
+    if err != nil {
+        return Decklist{}, err
+    }
+    return ret, nil
+}
+
+
+func (self SideboardPlan) resolved() (SideboardPlan, error) {
+    ret := SideboardPlan{In: make(map[string]int), Out: make(map[string]int)}
+    for name, n := range self.In {
+        if n <= 0 {
+            return ret, fmt.Errorf("%w: %d copies of %s", ErrBadPlan, n, name)
+        }
+        resolved, err := ResolveCardName(name)
+        if err != nil {
+            return ret, err
+        }
+        ret.In[resolved] += n
+    }
+    for name, n := range self.Out {
+        if n <= 0 {
+            return ret, fmt.Errorf("%w: %d copies of %s", ErrBadPlan, n, name)
+        }
+        resolved, err := ResolveCardName(name)
+        if err != nil {
+            return ret, err
+        }
+        ret.Out[resolved] += n
+    }
+    return ret, nil
+}
+
+
+// How many copies of each name
+func countNames(names []string) map[string]int {
+    counts := make(map[string]int)
+    for _, name := range names {
+        counts[name]++
+    }
+    return counts
+}
+
+
+// Distinct names with at least one copy, sorted
+func sortedNames(counts map[string]int) []string {
+    names := []string{}
+    for name, n := range counts {
+        if n > 0 {
+            names = append(names, name)
+        }
+    }
+    sort.Strings(names)
+    return names
+}
+
+
+// Back to a sorted list, one entry per copy
+func namesFromCounts(counts map[string]int) []string {
+    names := []string{}
+    for _, name := range sortedNames(counts) {
+        for i := 0; i < counts[name]; i++ {
+            names = append(names, name)
+        }
+    }
+    return names
+}
@@ -0,0 +1,78 @@
+package lib
+
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+
+func TestWithPlan(t *testing.T) {
+    deck, err := LoadDecklist(DefaultDeck)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // A fifth Titan in the sideboard, to try going over the limit
+    extra := deck
+    extra.Sideboard = append(append([]string{}, deck.Sideboard...), "Primeval Titan")
+    cases := []struct {
+        name string
+        deck Decklist
+        plan SideboardPlan
+        badPlan bool
+        ok bool
+    }{
+        {"swap", deck, SideboardPlan{In: map[string]int{"Force of Vigor": 2}, Out: map[string]int{"Explore": 2}}, false, true},
+        {"loose names", deck, SideboardPlan{In: map[string]int{"force of vigor": 1}, Out: map[string]int{"explore": 1}}, false, true},
+        {"negative out", deck, SideboardPlan{Out: map[string]int{"Primeval Titan": -10}}, true, false},
+        {"negative in", deck, SideboardPlan{In: map[string]int{"Force of Vigor": -3}}, true, false},
+        {"zero", deck, SideboardPlan{Out: map[string]int{"Explore": 0}}, true, false},
+        {"more out than there are", deck, SideboardPlan{Out: map[string]int{"Explore": 5}, In: map[string]int{"Force of Vigor": 3, "Dismember": 2}}, false, false},
+        {"more in than there are", deck, SideboardPlan{In: map[string]int{"Force of Vigor": 4}, Out: map[string]int{"Explore": 4}}, false, false},
+        {"too few left", deck, SideboardPlan{Out: map[string]int{"Explore": 1}}, false, false},
+        {"too many copies", extra, SideboardPlan{In: map[string]int{"Primeval Titan": 1}, Out: map[string]int{"Explore": 1}}, true, false},
+    }
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            got, err := tc.deck.WithPlan(tc.plan)
+            if tc.ok {
+                if err != nil {
+                    t.Fatal(err)
+                }
+                if len(got.Main) != len(tc.deck.Main) || len(got.Sideboard) != len(tc.deck.Sideboard) {
+                    t.Errorf("got %d/%d cards, want %d/%d", len(got.Main), len(got.Sideboard), len(tc.deck.Main), len(tc.deck.Sideboard))
+                }
+                return
+            }
+            if err == nil {
+                t.Fatal("plan should have been rejected")
+            }
+            if tc.badPlan && !errors.Is(err, ErrBadPlan) {
+                t.Errorf("got %v, want %v", err, ErrBadPlan)
+            }
+        })
+    }
+}
+
+
+// Data directory decks aren't checked on the way in, so a short one has to be
+// caught when it's loaded
+func TestLoadDecklistTooSmall(t *testing.T) {
+    dir := t.TempDir()
+    SetDataFiles(os.DirFS(".."), dir)
+    defer SetDataFiles(os.DirFS(".."), "..")
+    err := os.MkdirAll(filepath.Join(dir, deckDir), 0755)
+    if err != nil {
+        t.Fatal(err)
+    }
+    err = os.WriteFile(filepath.Join(dir, deckDir, "short.txt"), []byte("4 Forest\n"), 0644)
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, err = LoadDecklist("short")
+    if !errors.Is(err, ErrDeckTooSmall) {
+        t.Errorf("got %v, want %v", err, ErrDeckTooSmall)
+    }
+}
@@ -0,0 +1,67 @@
+package lib
+
+
+// On top of dropping exact duplicates, the search drops states that can never
+// do better than some other state in the same turn. If two states have the
+// same hand and library, and one has everything on the battlefield the other
+// does plus more, at least as much mana, and at least as many land plays,
+// anything the worse one can do the better one can do too. This matters most
+// with Amulet and bounce lands, where there are lots of orders to play lands
+// in that end up almost but not quite the same.
+
+
+// Only ever turned off by tests, to check that pruning never costs a line
+var pruneDominated = true
+
+
+// Everything that has to match exactly for one state to dominate another.
+// States are only compared within a bucket with the same key. Since this is a
+// hash, dominates still checks the fields for real.
+func (state *gameState) dominanceKey() uint64 {
+    h := zobristCardMap(zobristHand, state.hand) ^
+        zobristBool(zobristSuccess, state.success) ^
+        zobristBool(zobristDeadEnd, state.deadEnd) ^
+        zobristKey(zobristScalar, zobristOpponentSpent, state.opponent.spent) ^
+        zobristKey(zobristScalar, zobristOpponentDestroyed, state.opponent.destroyed)
+    for i, c := range state.library.arr {
+        h ^= zobristKey(zobristLibrary, uint64(i), uint64(c.id))
+    }
+    return h
+}
+
+
+// True if self is at least as good as other in every way
+func (self *gameState) dominates(other *gameState) bool {
+    if self.turn != other.turn ||
+        self.success != other.success ||
+        self.deadEnd != other.deadEnd ||
+        self.opponent.spent != other.opponent.spent ||
+        self.opponent.destroyed != other.opponent.destroyed {
+        return false
+    }
+    if self.manaPool.Green < other.manaPool.Green || self.manaPool.Total < other.manaPool.Total {
+        return false
+    }
+    // Owing less for Pact is better
+    if self.manaDebt.Green > other.manaDebt.Green || self.manaDebt.Total > other.manaDebt.Total {
+        return false
+    }
+    if self.landPlays < other.landPlays {
+        return false
+    }
+    // Spells cast this turn make the next one cost more under Damping Sphere
+    if self.spellsCast > other.spellsCast {
+        return false
+    }
+    if !self.hand.Equals(other.hand) || !self.library.Equals(other.library) {
+        return false
+    }
+    if !self.battlefield.Contains(other.battlefield) {
+        return false
+    }
+    // The model won't pass the turn with a land drop or an always_cast spell
+    // left over, so more land plays or mana can force a play the other state
+    // gets to hold for later
+    return self.skippedLandDrop() == other.skippedLandDrop() &&
+        self.skippedSpell() == other.skippedSpell()
+}
@@ -0,0 +1,152 @@
+package lib
+
+
+import (
+    "testing"
+)
+
+
+// A state partway through a turn, with the given hand and battlefield. The
+// library is the same for every state, so only what's set here differs.
+func testState(t *testing.T, hand []string, battlefield []string) gameState {
+    t.Helper()
+    cards := cardDataCache().layer(DefaultDeck)
+    toCards := func(names []string) []card {
+        ret := []card{}
+        for _, name := range names {
+            if _, ok := cards.lookup(name); !ok {
+                t.Fatalf("no card data for %s", name)
+            }
+            ret = append(ret, cards.card(name))
+        }
+        return ret
+    }
+    library := toCards([]string{"Forest", "Explore", "Wastes", "Primeval Titan", "Simic Growth Chamber"})
+    state := NewGameState(cards, library, toCards(hand), true, false, DefaultBudget(), opponent{})
+    state.turn = 2
+    state.battlefield = CardMap(toCards(battlefield))
+    return state
+}
+
+
+func TestDominates(t *testing.T) {
+    titan := []string{"Primeval Titan"}
+    lands := []string{"Forest", "Simic Growth Chamber"}
+    cases := []struct {
+        name string
+        // Changes to make to the better and worse states
+        better func(*gameState)
+        worse func(*gameState)
+        hand []string
+        dominates bool
+        // Whether worse also dominates better, like for identical states
+        both bool
+    }{
+        {"same", nil, nil, titan, true, true},
+        {"superset battlefield", func(s *gameState) {
+            s.battlefield = s.battlefield.Plus(s.card("Amulet of Vigor"))
+        }, nil, titan, true, false},
+        {"different battlefield", func(s *gameState) {
+            s.battlefield = s.battlefield.Plus(s.card("Amulet of Vigor"))
+        }, func(s *gameState) {
+            s.battlefield = s.battlefield.Plus(s.card("Dryad of the Ilysian Grove"))
+        }, titan, false, false},
+        {"more mana", func(s *gameState) {
+            s.manaPool = Mana("GG")
+        }, func(s *gameState) {
+            s.manaPool = Mana("G")
+        }, titan, true, false},
+        {"more colorless but less green", func(s *gameState) {
+            s.manaPool = Mana("3")
+        }, func(s *gameState) {
+            s.manaPool = Mana("G")
+        }, titan, false, false},
+        {"less debt", nil, func(s *gameState) {
+            s.manaDebt = Mana("2GG")
+        }, titan, true, false},
+        {"more land plays", func(s *gameState) {
+            s.landPlays = 2
+        }, func(s *gameState) {
+            s.landPlays = 1
+        }, titan, true, false},
+        // Damping Sphere makes each spell cost more than the last
+        {"fewer spells cast", nil, func(s *gameState) {
+            s.spellsCast = 1
+        }, titan, true, false},
+        {"different hand", nil, func(s *gameState) {
+            s.hand = s.hand.Plus(s.card("Explore"))
+        }, titan, false, false},
+        {"different turn", nil, func(s *gameState) {
+            s.turn = 3
+        }, titan, false, false},
+        // With a land in hand, the extra land play means the better state
+        // can't pass the turn, while the worse one can
+        {"land play parity", func(s *gameState) {
+            s.landPlays = 1
+        }, nil, lands, false, false},
+        // Same for having the mana for an always_cast spell
+        {"spell parity", func(s *gameState) {
+            s.manaPool = Mana("GGGGGG")
+        }, func(s *gameState) {
+            s.manaPool = Mana("GGGGG")
+        }, titan, false, false},
+        {"no parity problem without a land in hand", func(s *gameState) {
+            s.landPlays = 1
+        }, nil, titan, true, false},
+    }
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            better := testState(t, tc.hand, []string{"Forest", "Forest"})
+            worse := testState(t, tc.hand, []string{"Forest", "Forest"})
+            if tc.better != nil {
+                tc.better(&better)
+            }
+            if tc.worse != nil {
+                tc.worse(&worse)
+            }
+            if got := better.dominates(&worse); got != tc.dominates {
+                t.Errorf("better dominates worse: got %v, want %v", got, tc.dominates)
+            }
+            if got := worse.dominates(&better); got != tc.both {
+                t.Errorf("worse dominates better: got %v, want %v", got, tc.both)
+            }
+        })
+    }
+}
+
+
+// Dropping dominated states should only ever save work, never a line
+func TestPruningAgrees(t *testing.T) {
+    deck, err := LoadDecklist(DefaultDeck)
+    if err != nil {
+        t.Fatal(err)
+    }
+    budget := DefaultBudget()
+    budget.MaxTurns = 4
+    defer func() {
+        pruneDominated = true
+    }()
+    for _, seed := range hardHandSeeds {
+        shuffled := ShuffledSeed(deck.Main, seed)
+        turns := []int{}
+        for _, prune := range []bool{true, false} {
+            pruneDominated = prune
+            game, err := NewGame(deck.Name, shuffled[7:], shuffled[:7], false, false, budget, OpponentProfile{}, seed)
+            if err != nil {
+                t.Fatal(err)
+            }
+            game, err = game.Run(SearchBreadthFirst)
+            if err != nil {
+                t.Fatal(err)
+            }
+            turn := -1
+            if game.success {
+                turn = game.turn
+            }
+            turns = append(turns, turn)
+        }
+        if turns[0] != turns[1] {
+            t.Errorf("seed %d: pruned got turn %d, unpruned got turn %d", seed, turns[0], turns[1])
+        }
+    }
+}
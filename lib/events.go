@@ -0,0 +1,315 @@
+package lib
+
+
+import (
+    "strconv"
+)
+
+
+// Each gameState can replay the sequence of events that led to it. Events are
+// typed and carry a snapshot of the zones as of just after they happened, so
+// any output format can be rendered from them after the fact: the tag list the
+// frontend turns into HTML, the ANSI replay for the terminal, and so on.
+
+
+type eventType string
+
+
+const (
+    eventGameStart eventType = "game_start"
+    eventTurnStart eventType = "turn_start"
+    eventDraw eventType = "draw"
+    eventPlayLand eventType = "play_land"
+    // Putting a land onto the battlefield with Arboreal Grazer
+    eventPutLand eventType = "put_land"
+    eventCast eventType = "cast"
+    eventCountered eventType = "countered"
+    eventActivate eventType = "activate"
+    eventManaChange eventType = "mana_change"
+    eventMill eventType = "mill"
+    eventReveal eventType = "reveal"
+    // Choosing a mode, or picking a card out of a mill or reveal. A choice
+    // with no text and no cards is a whiff.
+    eventChoose eventType = "choose"
+    eventBounce eventType = "bounce"
+    eventPactPayment eventType = "pact_payment"
+    eventSagaChapter eventType = "saga_chapter"
+    eventSacrifice eventType = "sacrifice"
+    // Our Force of Vigor destroying the opponent's hate pieces
+    eventDestroy eventType = "destroy"
+    eventOpponentDiscard eventType = "opponent_discard"
+    eventOpponentRemoval eventType = "opponent_removal"
+    eventOpponentHate eventType = "opponent_hate"
+    eventGiveUp eventType = "give_up"
+)
+
+
+type event struct {
+    kind eventType
+    turn int
+    cards []card
+    text string
+    // Zones as of just after the event
+    hand cardMap
+    battlefield cardMap
+    manaPool mana
+    librarySize int
+}
+
+
+// States don't carry their own copy of the log. Each one points at the last
+// event that led to it, and each event points at the one before, so clones
+// share everything up to where they diverged. The full replay is only put
+// together when a finished state gets rendered.
+type eventNode struct {
+    event
+    parent *eventNode
+    depth int
+}
+
+
+// Record an event, snapshotting the zones from the current state. Card maps
+// are never modified in place, so the snapshot can share them.
+func (self *gameState) record(kind eventType, text string, cards ...card) {
+    node := eventNode{
+        event: event{
+            kind: kind,
+            turn: self.turn,
+            cards: cards,
+            text: text,
+            hand: self.hand,
+            battlefield: self.battlefield,
+            manaPool: self.manaPool,
+            librarySize: len(self.library.arr),
+        },
+        parent: self.lastEvent,
+    }
+    if self.lastEvent != nil {
+        node.depth = self.lastEvent.depth + 1
+    }
+    self.lastEvent = &node
+}
+
+
+// Walk back through the parents to get every event, oldest first
+func (self *gameState) replay() []event {
+    if self.lastEvent == nil {
+        return []event{}
+    }
+    events := make([]event, self.lastEvent.depth+1)
+    for node := self.lastEvent; node != nil; node = node.parent {
+        events[node.depth] = node.event
+    }
+    return events
+}
+
+
+// Exported form of an event, for clients that want structured output
+type Event struct {
+    Type string                 `json:"type"`
+    Turn int                    `json:"turn"`
+    Cards []string              `json:"cards,omitempty"`
+    Text string                 `json:"text,omitempty"`
+    Hand map[string]int         `json:"hand"`
+    Battlefield map[string]int  `json:"battlefield"`
+    ManaPool string             `json:"manaPool"`
+    LibrarySize int             `json:"librarySize"`
+}
+
+
+func (self *event) export() Event {
+    cards := []string{}
+    for _, c := range self.cards {
+        cards = append(cards, c.Name())
+    }
+    return Event{
+        Type: string(self.kind),
+        Turn: self.turn,
+        Cards: cards,
+        Text: self.text,
+        Hand: self.hand.Names(),
+        Battlefield: self.battlefield.Names(),
+        ManaPool: self.manaPool.Pretty(),
+        LibrarySize: self.librarySize,
+    }
+}
+
+
+// Build up a list of tags. Consecutive bits of text are merged into a single
+// text tag.
+type tagBuilder struct {
+    tags []PlayTag
+    text string
+}
+
+
+func (self *tagBuilder) addText(s string) {
+    self.text += s
+}
+
+
+func (self *tagBuilder) flush() {
+    if self.text != "" {
+        self.tags = append(self.tags, Tag("text", self.text, ""))
+        self.text = ""
+    }
+}
+
+
+func (self *tagBuilder) addBreak() {
+    self.flush()
+    self.tags = append(self.tags, Tag("break", "", ""))
+}
+
+
+func (self *tagBuilder) addCard(c card) {
+    self.flush()
+    self.tags = append(self.tags, c.Tag())
+}
+
+
+func (self *tagBuilder) addMana(m mana) {
+    self.flush()
+    self.tags = append(self.tags, m.Tag())
+}
+
+
+func (self *tagBuilder) addCards(cards []card) {
+    cm := CardMap(cards)
+    first := true
+    for _, c := range cm.Items() {
+        n := cm.Count(c)
+        if !first {
+            self.addText(" ")
+        }
+        first = false
+        if n > 1 {
+            // TODO: Use the unicode multiplication symbol instead
+            self.addText(strconv.Itoa(n) + "*")
+        }
+        self.addCard(c)
+    }
+}
+
+
+func (self *tagBuilder) addManaPool(m mana, verbose bool) {
+    if verbose && m.Total > 0 {
+        self.addText(", ")
+        self.addMana(m)
+        self.addText(" in pool")
+    }
+}
+
+
+// Render events as the tag list the frontend expects. Mana pool updates are
+// only included in verbose mode.
+func renderTags(events []event, verbose bool) []PlayTag {
+    b := tagBuilder{}
+    for _, e := range events {
+        switch e.kind {
+            case eventGameStart:
+                b.addText(e.text + ", opening hand: ")
+                b.addCards(e.hand.Cards())
+            case eventTurnStart:
+                b.addBreak()
+                b.addText("turn " + strconv.Itoa(e.turn))
+            case eventDraw:
+                b.addText(", draw ")
+                b.addCards(e.cards)
+            case eventPlayLand:
+                b.addBreak()
+                b.addText("play ")
+                b.addCard(e.cards[0])
+            case eventPutLand:
+                b.addText(", play ")
+                b.addCard(e.cards[0])
+            case eventCast:
+                b.addBreak()
+                b.addText("cast ")
+                b.addCard(e.cards[0])
+                // Titan ends the game, and Pact is free
+                if e.cards[0].Name() != "Primeval Titan" && e.cards[0].Name() != "Summoner's Pact" {
+                    b.addManaPool(e.manaPool, verbose)
+                }
+            case eventCountered:
+                b.addText(", countered by " + e.text)
+            case eventActivate:
+                b.addBreak()
+                b.addText("activate ")
+                b.addCard(e.cards[0])
+                b.addManaPool(e.manaPool, verbose)
+            case eventManaChange:
+                b.addManaPool(e.manaPool, verbose)
+            case eventMill:
+                b.addText(", mill ")
+                b.addCards(e.cards)
+            case eventReveal:
+                b.addText(", reveal")
+                for _, c := range e.cards {
+                    b.addText(" ")
+                    b.addCard(c)
+                }
+            case eventChoose:
+                if e.text != "" {
+                    b.addText(", choose " + e.text)
+                } else if len(e.cards) > 0 {
+                    b.addText(", grab ")
+                    b.addCard(e.cards[0])
+                } else {
+                    b.addText(", whiff")
+                }
+            case eventBounce:
+                b.addText(", bounce ")
+                b.addCard(e.cards[0])
+            case eventPactPayment:
+                b.addText(", pay for pact")
+                b.addManaPool(e.manaPool, verbose)
+            case eventSagaChapter:
+                b.addText(", ")
+                b.addCard(e.cards[0])
+                b.addText(" to " + e.text)
+                if len(e.cards) > 1 {
+                    b.addText(" gets ")
+                    b.addCard(e.cards[1])
+                }
+            case eventSacrifice:
+                b.addText(", sacrifice ")
+                b.addCard(e.cards[0])
+            case eventDestroy:
+                if e.text == "" {
+                    b.addText(", no targets")
+                } else {
+                    b.addText(", destroy " + e.text)
+                }
+            case eventOpponentDiscard:
+                b.addBreak()
+                b.addText("opponent casts " + e.text)
+                if len(e.cards) > 0 {
+                    b.addText(", takes ")
+                    b.addCard(e.cards[0])
+                } else {
+                    b.addText(", whiff")
+                }
+            case eventOpponentRemoval:
+                b.addBreak()
+                b.addText("opponent casts " + e.text + ", removes ")
+                b.addCard(e.cards[0])
+            case eventOpponentHate:
+                b.addBreak()
+                b.addText("opponent casts " + e.text)
+            case eventGiveUp:
+                if e.text != "" {
+                    b.addBreak()
+                    b.addText(e.text)
+                    for _, c := range e.cards {
+                        b.addText(" ")
+                        b.addCard(c)
+                    }
+                }
+                b.addBreak()
+                b.addText("giving up!")
+        }
+    }
+    b.flush()
+    return b.tags
+}
@@ -2,62 +2,54 @@ package lib
 
 
 import (
-    "encoding/json"
-    "log"
     "strings"
 )
 
 
-type report struct {
-    Turn    int     `json:"turn"`
-    Plays   []tag   `json:"plays"`
-}
-
-
-type tag struct {
+type PlayTag struct {
     Type string   `json:"type"`
     Text string   `json:"text"`
     Target string `json:"target"`
 }
 
 
-func Tag(tagType string, display string, target string) tag {
-    return tag{Type: tagType, Text: display, Target: target}
+func Tag(tagType string, display string, target string) PlayTag {
+    return PlayTag{Type: tagType, Text: display, Target: target}
 }
 
 
-func (self *tag) ToJSON() string {
-    b, err := json.Marshal(self)
-    if err != nil {
-        log.Fatal("failed to marshal:", self)
+// Render tags for the terminal. Without color, card names are spelled out
+// rather than slugged, since there's nothing else to set them apart.
+func prettyTags(tags []PlayTag, success bool, color bool) string {
+    paint := func(code string, text string) string {
+        if !color {
+            return text
+        }
+        return "\u001b[" + code + "m" + text + "\u001b[0m"
     }
-    return string(b)
-}
-
-
-func PrettyJSON(s string) string {
-    rep := report{}
-    err := json.Unmarshal([]byte(s), &rep)
-    if err != nil {
-        log.Fatal("failed to unmarshal:", s)
+    cardName := func(code string, t PlayTag) string {
+        if !color {
+            return t.Target
+        }
+        return paint(code, slug(t.Text))
     }
     ret := ""
-    for _, t := range rep.Plays {
+    for _, t := range tags {
         if t.Type == "text" {
             ret += t.Text
         } else if t.Type == "break" {
             ret += "\n"
         } else if t.Type == "mana" {
-            ret += "\u001b[35m" + t.Text + "\u001b[0m"
+            ret += paint("35", t.Text)
         } else if t.Type == "land" {
-            ret += "\u001b[33m" + slug(t.Text) + "\u001b[0m"
+            ret += cardName("33", t)
         } else if t.Type == "spell" {
-            ret += "\u001b[32m" + slug(t.Text) + "\u001b[0m"
+            ret += cardName("32", t)
         } else {
-            log.Fatal("not sure how to export type", t.Type)
+            ret += t.Text
         }
     }
-    if rep.Turn > 0 {
+    if success {
         ret += "\nSUCCESS"
     } else {
         ret += "\nFAILURE"
@@ -2,44 +2,98 @@ package lib
 
 
 import (
-    "log"
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "math/rand"
     "strings"
 )
 
 
+var ErrNoStates = errors.New("no game states left")
+
+
 type gameManager struct {
     maxTurns int
     // Use a map to imitate a Python-style set of game states
-    states map[string]gameState
+    states map[uint64]gameState
+    // State hashes grouped by dominance key. Entries for states that have
+    // since been popped or pruned are cleaned up lazily.
+    buckets map[uint64][]uint64
+    // Shared by every manager over the course of one game
+    stats *SearchStats
     success bool
     turn int
 }
 
 
-func NewGame(libraryRaw []string, handRaw []string, otp bool, verbose bool, maxTurns int) (gameManager, error) {
-    allCardNames := []string{}
+// Set up a game with the given deck's card data. An empty deck name means
+// the default deck. The seed decides which of the opponent's cards show up.
+func NewGame(deck string, libraryRaw []string, handRaw []string, otp bool, verbose bool, budget Budget, profile OpponentProfile, seed int64) (gameManager, error) {
+    // Names may have been typed by a person, so clean them up first
+    libraryRaw, err := ResolveCardNames(libraryRaw)
+    if err != nil {
+        return gameManager{}, err
+    }
+    handRaw, err = ResolveCardNames(handRaw)
+    if err != nil {
+        return gameManager{}, err
+    }
+    if deck == "" {
+        deck = DefaultDeck
+    }
+    err = EnsureCardData(deck, append(append([]string{}, handRaw...), libraryRaw...))
+    if err != nil {
+        return gameManager{}, err
+    }
+    cards := cardDataCache().layer(deck)
     handCards := []card{}
     for _, cardName := range handRaw {
-        handCards = append(handCards, Card(cardName))
-        allCardNames = append(allCardNames, cardName)
+        handCards = append(handCards, cards.card(cardName))
     }
     libraryCards := []card{}
     for _, cardName := range libraryRaw {
-        libraryCards = append(libraryCards, Card(cardName))
-        allCardNames = append(allCardNames, cardName)
+        libraryCards = append(libraryCards, cards.card(cardName))
+    }
+    draws := budget.MaxTurns
+    if otp {
+        draws -= 1
     }
-    err := EnsureCardData(allCardNames)
+    if len(libraryCards) < draws {
+        return gameManager{}, fmt.Errorf("%w: %d turns need %d cards, have %d", ErrLibraryExhausted, budget.MaxTurns, draws, len(libraryCards))
+    }
+    // Catch anything the engine can't play before starting the search
+    all := CardMap(append(append([]card{}, handCards...), libraryCards...))
+    if err := all.Err(); err != nil {
+        return gameManager{}, err
+    }
+    for _, c := range all.Items() {
+        if !c.IsVanilla() && !hasBehavior(c) {
+            return gameManager{}, fmt.Errorf("%w: %s", ErrNoBehavior, c.Name())
+        }
+    }
+    opp, err := Opponent(profile, opponentRand(seed))
     if err != nil {
         return gameManager{}, err
     }
-    state := NewGameState(libraryCards, handCards, otp, verbose, maxTurns)
+    state := NewGameState(cards, libraryCards, handCards, otp, verbose, budget, opp)
     return GameManager(state), nil
 }
 
 
+// The seed also shuffles the library. Salting it keeps the opponent's rolls
+// from lining up with the shuffle. The salt is "opponent" in ASCII.
+func opponentRand(seed int64) *rand.Rand {
+    return rand.New(rand.NewSource(seed ^ 0x6f70706f6e656e74))
+}
+
+
 func GameManager(states ...gameState) gameManager {
     manager := gameManager{
-        states: make(map[string]gameState),
+        states: make(map[uint64]gameState),
+        buckets: make(map[uint64][]uint64),
+        stats: &SearchStats{log: slog.Default(), ctx: context.Background()},
     }
     for _, state := range states {
         manager.Add(state)
@@ -48,24 +102,44 @@ func GameManager(states ...gameState) gameManager {
 }
 
 
-func (self *gameManager) NextTurn() gameManager {
+func (self *gameManager) NextTurn() (gameManager, error) {
     if self.Size() == 0 {
-        log.Fatal("called NextTurn on empty gameManager")
+        return *self, ErrNoStates
     }
     // Once we find a line, we're done iterating
     if self.success {
-        return *self
+        return *self, nil
     }
     if self.turn > 0 {
-        log.Println("starting turn", self.turn, "with", self.Size(), "states")
+        self.stats.log.Debug("starting turn", "turn", self.turn, "states", self.Size())
+        self.stats.reportTurn(self.turn, self.Size())
     }
+    prunedBefore := self.stats.Pruned
+    defer func() {
+        if pruned := self.stats.Pruned - prunedBefore; pruned > 0 {
+            self.stats.log.Debug("pruned dominated states", "turn", self.turn, "pruned", pruned)
+        }
+    }()
     ret := GameManager()
+    ret.stats = self.stats
     for self.Size() > 0 {
-        stateOld := self.Pop()
-        for _, stateNew := range stateOld.NextStates() {
+        stateOld, _ := self.Pop()
+        self.stats.Expanded += 1
+        live := !stateOld.deadEnd
+        if self.stats.exhausted(stateOld.budget) {
+            stateOld.giveUp("out of search budget")
+        }
+        next, err := stateOld.NextStates()
+        if err != nil {
+            return *self, err
+        }
+        self.stats.countGiveUps(live, &stateOld, next)
+        for _, stateNew := range next {
             // If we find a state that gets there, we're done
             if stateNew.success {
-                return GameManager(stateNew)
+                ret := GameManager(stateNew)
+                ret.stats = self.stats
+                return ret, nil
             }
             if stateNew.turn == self.turn {
                 self.Add(stateNew)
@@ -77,51 +151,52 @@ func (self *gameManager) NextTurn() gameManager {
     // After turn four or so, further work is expensive but not interesting.
     // Pop off the longest log we can find to show we tried.
     if ret.turn > self.maxTurns {
-        log.Println("giving up on turn", ret.turn, "with", ret.Size(), "states")
-        bestState := ret.Pop()
+        self.stats.log.Debug("giving up", "turn", ret.turn, "states", ret.Size())
+        bestState, _ := ret.Pop()
         for ret.Size() > 0 {
-            state := ret.Pop()
+            state, _ := ret.Pop()
             if state.LogSize() > bestState.LogSize() {
                 bestState = state
             }
         }
-        bestState.MarkDeadEnd()
-        return GameManager(bestState)
+        bestState.giveUp("")
+        ret = GameManager(bestState)
+        ret.stats = self.stats
     }
-    return ret
+    return ret, nil
 }
 
 
-func (self *gameManager) Pretty() string {
-    lines := []string{}
-    for _, state := range self.states {
-        lines = append(lines, state.Pretty())
-    }
-    return strings.Join(lines, "\n~~~\n")
-}
-
-
-func (self *gameManager) ToJSON() string {
-    lines := []string{}
-    for _, state := range self.states {
-        lines = append(lines, state.ToJSON())
+// Log the search with l, like to tag every line with a request ID
+func (self *gameManager) SetLogger(l *slog.Logger) {
+    self.stats.log = l
+    for hash, state := range self.states {
+        state.log = l
+        self.states[hash] = state
     }
-    return strings.Join(lines, "\n~~~\n")
 }
 
 
-func (self *gameManager) ToMiniJSON() string {
+func (self *gameManager) Pretty() string {
     lines := []string{}
     for _, state := range self.states {
-        lines = append(lines, state.ToMiniJSON())
+        lines = append(lines, state.Pretty())
     }
     return strings.Join(lines, "\n~~~\n")
 }
 
 
 func (self *gameManager) Add(state gameState) {
-    self.states[state.Hash()] = state
-    self.maxTurns = state.maxTurns
+    hash := state.Hash()
+    if _, ok := self.states[hash]; ok {
+        return
+    }
+    if !self.addUndominated(hash, &state) {
+        self.stats.Pruned += 1
+        return
+    }
+    self.states[hash] = state
+    self.maxTurns = state.budget.MaxTurns
     // By construction, in-progress states and completed states never mix
     self.success = state.success
     // Turn is uniform for all states within a gameManager
@@ -129,13 +204,41 @@ func (self *gameManager) Add(state gameState) {
 }
 
 
-func (self *gameManager) Pop() gameState {
+// Compare a new state against the others in its bucket. Returns false if one
+// of them dominates it. Otherwise, drops any it dominates and adds it to the
+// bucket.
+func (self *gameManager) addUndominated(hash uint64, state *gameState) bool {
+    if !pruneDominated {
+        return true
+    }
+    key := state.dominanceKey()
+    bucket := []uint64{}
+    for _, other := range self.buckets[key] {
+        otherState, ok := self.states[other]
+        if !ok {
+            continue
+        }
+        if otherState.dominates(state) {
+            return false
+        }
+        if state.dominates(&otherState) {
+            delete(self.states, other)
+            self.stats.Pruned += 1
+            continue
+        }
+        bucket = append(bucket, other)
+    }
+    self.buckets[key] = append(bucket, hash)
+    return true
+}
+
+
+func (self *gameManager) Pop() (gameState, error) {
     for hash, state := range self.states {
         delete(self.states, hash)
-        return state
+        return state, nil
     }
-    log.Fatal("pop from empty gameManager")
-    return gameState{}
+    return gameState{}, ErrNoStates
 }
 
 
@@ -150,7 +253,7 @@ func (self *gameManager) IsDone() bool {
 
 
 func (self *gameManager) Update(other gameManager) {
-    for hash, state := range other.states {
-        self.states[hash] = state
+    for _, state := range other.states {
+        self.Add(state)
     }
 }
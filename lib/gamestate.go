@@ -2,29 +2,41 @@ package lib
 
 
 import (
-    "encoding/json"
-    "log"
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
     "strings"
-    "strconv"
 )
 
 
+// The engine has card data for it, but doesn't know what it does
+var ErrNoBehavior = errors.New("no behavior for card")
+
+
 // The gameState is an immutable object which describes a snapshot in time
 // during a game. Any change in game state, like drawing a card or casting a
 // spell, is enacted by creating a new state.
 type gameState struct {
     battlefield cardMap
+    budget *Budget
+    // The deck's card data, shared by every state in the game
+    cards *cardLayer
     deadEnd bool
+    // Set when a line can't be played out, like drawing from an empty
+    // library. That's a problem with the request, not a missed line.
+    err error
     hand cardMap
-    hash string
     landPlays int
+    lastEvent *eventNode
     library cardArray
-    jsonCache string
-    jsonLog string
+    // Shared by every state in a game, so lines can be traced to a request
+    log *slog.Logger
     manaDebt mana
     manaPool mana
-    maxTurns int
     onThePlay bool
+    opponent opponent
+    spellsCast int
     success bool
     timestamp int64
     turn int
@@ -32,38 +44,73 @@ type gameState struct {
 }
 
 
-func NewGameState(library []card, hand []card, otp bool, verbose bool, maxTurns int) gameState {
+func NewGameState(cards *cardLayer, library []card, hand []card, otp bool, verbose bool, budget Budget, opp opponent) gameState {
     state := gameState{
+        budget: &budget,
+        cards: cards,
         hand: CardMap(hand),
-        // Empty string is fine for the initial game state
-        hash: "",
         landPlays: 0,
         library: CardArray(library),
-        maxTurns: maxTurns,
+        log: slog.Default(),
         onThePlay: otp,
+        opponent: opp,
         timestamp: timestamp(),
         turn: 0,
         verbose: verbose,
     }
     if otp {
-        state.logText("on the play")
+        state.record(eventGameStart, "on the play")
     } else {
-        state.logText("on the draw")
+        state.record(eventGameStart, "on the draw")
     }
-    state.logText(", opening hand: ")
-    state.logCardMap(state.hand)
     return state
 }
 
 
-func (self *gameState) NextStates() []gameState {
+// Engine code names cards by their shared names. This finds the deck's own
+// version, if it has one.
+func (self *gameState) card(name string) card {
+    return self.cards.card(name)
+}
+
+
+func (self *gameState) NextStates() ([]gameState, error) {
+    ret := self.nextStates()
+    for _, state := range ret {
+        if err := state.Err(); err != nil {
+            return nil, err
+        }
+    }
+    return ret, nil
+}
+
+
+// Anything that went wrong getting to this state
+func (self *gameState) Err() error {
+    if self.err != nil {
+        return self.err
+    }
+    if err := self.hand.Err(); err != nil {
+        return err
+    }
+    return self.battlefield.Err()
+}
+
+
+func (clone gameState) fail(err error) []gameState {
+    if clone.err == nil {
+        clone.err = err
+    }
+    return []gameState{clone}
+}
+
+
+func (self *gameState) nextStates() []gameState {
     ret := []gameState{}
     // If we're out of time, see about wrapping up gracefully. Note that
     // timestamp is measured in nanoseconds
-    if timestamp() - self.timestamp > 4e9 {
-        self.logBreak()
-        self.logText("timeout")
-        self.MarkDeadEnd()
+    if timestamp() - self.timestamp > int64(self.budget.Timeout) {
+        self.giveUp("timeout")
     }
     // If we've flagged this state as a dead end, just wait out the clock
     if self.deadEnd {
@@ -85,10 +132,10 @@ func (self *gameState) NextStates() []gameState {
     if !self.skippedLandDrop() && !self.skippedSpell() {
         ret = append(ret, self.passTurn()...)
     }
-    for c, _ := range self.hand.Items() {
+    for _, c := range self.hand.Items() {
         if c.IsLand() {
-            // No need to ever go above 6 mana
-            if self.manaPool.Total >= 6 {
+            // No need to go above the mana cap
+            if self.manaPool.Total >= self.budget.ManaCap {
                 continue
             }
             ret = append(ret, self.play(c)...)
@@ -97,8 +144,8 @@ func (self *gameState) NextStates() []gameState {
             ret = append(ret, self.cast(c)...)
         }
     }
-    for c, _ := range self.battlefield.Items() {
-        if c.HasAbility() {
+    for _, c := range self.battlefield.Items() {
+        if self.canActivate(c) {
             ret = append(ret, self.activate(c)...)
         }
     }
@@ -107,22 +154,19 @@ func (self *gameState) NextStates() []gameState {
 
 
 func (self *gameState) checkForFailure() []gameState {
-    if self.turn < self.maxTurns {
+    if self.turn < self.budget.MaxTurns {
         return []gameState{}
     }
     // If we don't have Primeval Titan or a way to find it, bail
     noTitan := true
-    for c, _ := range self.hand.Items() {
+    for _, c := range self.hand.Items() {
         if c.CanBeTitan() {
             noTitan = false
         }
     }
     if noTitan {
         clone := self.clone()
-        clone.logBreak()
-        clone.logText("failed to find ")
-        clone.logCard(Card("Primeval Titan"))
-        clone.MarkDeadEnd()
+        clone.giveUp("failed to find", self.card("Primeval Titan"))
         return clone.passTurn()
     }
     return []gameState{}
@@ -133,11 +177,11 @@ func (self *gameState) skippedLandDrop() bool {
     // Return true if all of the following are true:
     // 1. We have a non-bounce land in hand
     // 2. We have a land play remaining
-    // 3. We have less than 6 mana available
+    // 3. We have less mana available than the cap
     // Note: this has a very small chance to miss lines! For example, if we
     // have 2x Amulet we might want to untap before playing Bojuka Bog.
-    if self.landPlays > 0 && self.manaPool.Total < 6 {
-        for c, _ := range self.hand.Items() {
+    if self.landPlays > 0 && self.manaPool.Total < self.budget.ManaCap {
+        for _, c := range self.hand.Items() {
             if c.IsLand() && !c.IsBounceLand() {
                 return true
             }
@@ -153,8 +197,8 @@ func (self *gameState) skippedSpell() bool {
     // that non-human play pattern. We might want to hold onto Explore for ramp
     // purposes with multiple copies of Amulet, but a human player is never
     // going to pass the turn rather than cast Ancient Stirrings.
-    for c, _ := range self.hand.Items() {
-        if c.AlwaysCast() && self.manaPool.CanPay(c.CastingCost()) {
+    for _, c := range self.hand.Items() {
+        if c.AlwaysCast() && self.manaPool.CanPay(self.castingCost(c)) {
             return true
         }
     }
@@ -169,46 +213,42 @@ func (clone gameState) clone() gameState {
 
 func (clone gameState) passTurn() []gameState {
     clone.turn += 1
-    if clone.turn > clone.maxTurns {
-        // Nice to have output here in terms of traceability when debugging,
-        // but it doesn't read nicely.
-//        clone.logBreak()
-//        clone.logText("no more turns")
-        clone.MarkDeadEnd()
+    if clone.turn > clone.budget.MaxTurns {
+        // Nice to have a reason here in terms of traceability when
+        // debugging, but it doesn't read nicely.
+        clone.giveUp("")
         return []gameState{clone}
     }
-    clone.logBreak()
-    clone.logText("turn " + strconv.Itoa(clone.turn))
+    // Opponent gets their turn before we untap
+    clone = clone.opponentTurn()
+    clone.record(eventTurnStart, "")
     // Empty mana pool then tap out
     clone.manaPool = mana{}
-    nDryads := clone.battlefield.Count(Card("Dryad of the Ilysian Grove"))
-    for c, n := range clone.battlefield.Items() {
-        m := c.TapsFor()
+    clone.spellsCast = 0
+    nDryads := clone.battlefield.Count(clone.card("Dryad of the Ilysian Grove"))
+    for _, c := range clone.battlefield.Items() {
+        n := clone.battlefield.Count(c)
+        m := clone.tapsFor(c)
         if m == Mana("1") && nDryads > 0 {
             m = Mana("G")
         }
         clone.manaPool = clone.manaPool.Plus(m.Times(n))
     }
-    clone.logManaPool()
+    clone.record(eventManaChange, "")
     // Handling for Urza's Saga
-    for clone.battlefield.Count(Card("Urza's Saga (II)")) > 0 {
-        clone.logText(", " )
-        clone.logCard(Card("Urza's Saga (II)"))
-        clone.logText(" to III gets ")
-        clone.logCard(Card("Amulet of Vigor"))
+    for clone.battlefield.Count(clone.card("Urza's Saga (II)")) > 0 {
         clone.battlefield = clone.battlefield.Replace(
-            Card("Urza's Saga (II)"),
-            Card("Amulet of Vigor"),
+            clone.card("Urza's Saga (II)"),
+            clone.card("Amulet of Vigor"),
         )
+        clone.record(eventSagaChapter, "III", clone.card("Urza's Saga (II)"), clone.card("Amulet of Vigor"))
     }
-    for clone.battlefield.Count(Card("Urza's Saga")) > 0 {
-        clone.logText(", ")
-        clone.logCard(Card("Urza's Saga"))
-        clone.logText(" to II")
+    for clone.battlefield.Count(clone.card("Urza's Saga")) > 0 {
         clone.battlefield = clone.battlefield.Replace(
-            Card("Urza's Saga"),
-            Card("Urza's Saga (II)"),
+            clone.card("Urza's Saga"),
+            clone.card("Urza's Saga (II)"),
         )
+        clone.record(eventSagaChapter, "II", clone.card("Urza's Saga"))
     }
     // Pay for Pact
     if clone.manaDebt.Total > 0 {
@@ -218,13 +258,12 @@ func (clone gameState) passTurn() []gameState {
         }
         clone.manaPool = m
         clone.manaDebt = Mana("")
-        clone.logText(", pay for pact")
-        clone.logManaPool()
+        clone.record(eventPactPayment, "")
     }
     // Reset land drops. Check for Dryad, Scout, Azusa
     clone.landPlays = 1 + nDryads +
-        clone.battlefield.Count(Card("Sakura-Tribe Scout")) +
-        2*clone.battlefield.Count(Card("Azusa, Lost but Seeking"))
+        clone.battlefield.Count(clone.card("Sakura-Tribe Scout")) +
+        2*clone.battlefield.Count(clone.card("Azusa, Lost but Seeking"))
     if clone.turn > 1 || !clone.onThePlay {
         return clone.draw(1)
     } else {
@@ -245,17 +284,13 @@ func (clone gameState) activate(c card) []gameState {
         return []gameState{}
     }
     clone.manaPool = m
-    clone.logBreak()
-    clone.logText("activate ")
-    clone.logCard(c)
-    clone.logManaPool()
+    clone.record(eventActivate, "", c)
     // Now figure out what it does
-    switch c.name {
+    switch c.Name() {
         case "Castle Garenbrig":
             return clone.activateCastleGarenbrig()
     }
-    log.Fatal("not sure how to activate: " + c.name)
-    return []gameState{}
+    return clone.fail(fmt.Errorf("%w: %s", ErrNoBehavior, c.Name()))
 }
 
 
@@ -265,21 +300,29 @@ func (clone gameState) cast(c card) []gameState {
         return []gameState{}
     }
     // Do we have enough mana to cast it?
-    cost := c.CastingCost()
+    cost := clone.castingCost(c)
     m, err := clone.manaPool.Minus(cost)
     if err != nil {
         return []gameState{}
     }
     clone.manaPool = m
-    clone.logBreak()
-    clone.logText("cast ")
-    clone.logCard(c)
+    if c.IsSpell() {
+        clone.spellsCast += 1
+    }
     clone.hand = clone.hand.Minus(c)
-    if c.name != "Primeval Titan" && c.name != "Summoner's Pact" {
-        clone.logManaPool()
+    clone.record(eventCast, "", c)
+    // Does the opponent have an answer?
+    if clone.chaliceCounters(c) {
+        clone.record(eventCountered, "Chalice of the Void", c)
+        return []gameState{clone}
+    }
+    if i := clone.opponent.counterFor(c); i >= 0 {
+        clone.opponent.spend(i)
+        clone.record(eventCountered, clone.opponent.disruptions[i].Card, c)
+        return []gameState{clone}
     }
     // Now figure out what it does
-    switch c.name {
+    switch c.Name() {
         case "Abundant Harvest":
             return clone.castAbundantHarvest()
         case "Adventurous Impulse":
@@ -298,13 +341,17 @@ func (clone gameState) cast(c card) []gameState {
             return clone.castElvishSpiritGuide()
         case "Explore":
             return clone.castExplore()
+        case "Force of Vigor":
+            return clone.castForceOfVigor()
         case "Primeval Titan":
             return clone.castPrimevalTitan()
         case "Summoner's Pact":
             return clone.castSummonersPact()
     }
-    log.Fatal("not sure how to cast: " + c.name)
-    return []gameState{}
+    if c.IsVanilla() {
+        return clone.castVanilla(c)
+    }
+    return clone.fail(fmt.Errorf("%w: %s", ErrNoBehavior, c.Name()))
 }
 
 
@@ -318,17 +365,15 @@ func (clone gameState) play(c card) []gameState {
         return []gameState{}
     }
     clone.landPlays -= 1
-    clone.logBreak()
-    clone.logText("play ")
-    clone.logCard(c)
-    if c.name == "Castle Garenbrig" {
-        if clone.battlefield.Count(Card("Forest")) > 0 {
+    clone.record(eventPlayLand, "", c)
+    if c.Name() == "Castle Garenbrig" && !clone.isMountain(c) {
+        if clone.battlefield.Count(clone.card("Forest")) > 0 {
             return clone.playUntapped(c)
         } else {
             return clone.playTapped(c)
         }
     }
-    if c.EntersTapped() {
+    if clone.entersTapped(c) {
         return clone.playTapped(c)
     } else {
         return clone.playUntapped(c)
@@ -337,19 +382,19 @@ func (clone gameState) play(c card) []gameState {
 
 
 func (clone gameState) playTapped(c card) []gameState {
-    nAmulets := clone.battlefield.Count(Card("Amulet of Vigor"))
-    m := c.TapsFor()
+    nAmulets := clone.battlefield.Count(clone.card("Amulet of Vigor"))
+    m := clone.tapsFor(c)
     for i := 0; i < nAmulets; i++ {
         clone.manaPool = clone.manaPool.Plus(m)
-        clone.logManaPool()
+        clone.record(eventManaChange, "")
     }
     return clone.playHelper(c)
 }
 
 
 func (clone gameState) playUntapped(c card) []gameState {
-    clone.manaPool = clone.manaPool.Plus(c.TapsFor())
-    clone.logManaPool()
+    clone.manaPool = clone.manaPool.Plus(clone.tapsFor(c))
+    clone.record(eventManaChange, "")
     return clone.playHelper(c)
 }
 
@@ -357,8 +402,12 @@ func (clone gameState) playUntapped(c card) []gameState {
 func (clone gameState) playHelper(c card) []gameState {
     clone.hand = clone.hand.Minus(c)
     clone.battlefield = clone.battlefield.Plus(c)
+    // Under Blood Moon, lands have no additional effects
+    if clone.isMountain(c) {
+        return []gameState{clone.sacrificeSagas()}
+    }
     // Watch out for additional effects, if any
-    switch c.name {
+    switch c.Name() {
         case "Bojuka Bog":
             return clone.playBojukaBog()
         case "Castle Garenbrig":
@@ -378,21 +427,23 @@ func (clone gameState) playHelper(c card) []gameState {
         case "Valakut, the Molten Pinnacle":
             return clone.playValakut()
     }
-    log.Fatal("not sure how to play: " + c.name)
-    return []gameState{}
+    if c.IsVanilla() {
+        return []gameState{clone}
+    }
+    return clone.fail(fmt.Errorf("%w: %s", ErrNoBehavior, c.Name()))
 }
 
 
 func (clone gameState) activateCastleGarenbrig() []gameState {
     clone.manaPool = clone.manaPool.Plus(Mana("GGGGGG"))
-    clone.logManaPool()
+    clone.record(eventManaChange, "")
     // Only activate immediately before casting Titan
     ret := append(
-        clone.cast(Card("Primeval Titan")),
-        clone.cast(Card("Summoner's Pact"))...,
+        clone.cast(clone.card("Primeval Titan")),
+        clone.cast(clone.card("Summoner's Pact"))...,
     )
     for _, state := range ret {
-        if state.success {
+        if state.success || state.Err() != nil {
             return []gameState{state}
         }
     }
@@ -401,30 +452,29 @@ func (clone gameState) activateCastleGarenbrig() []gameState {
 
 
 func (clone gameState) castAmuletOfVigor() []gameState {
-    clone.battlefield = clone.battlefield.Plus(Card("Amulet of Vigor"))
+    clone.battlefield = clone.battlefield.Plus(clone.card("Amulet of Vigor"))
     return []gameState{clone}
 }
 
 
 func (self *gameState) castAdventurousImpulse() []gameState {
     ret := []gameState{}
-    milled_raw, remaining := self.library.SplitAfter(3)
+    // With fewer than three cards left, look at what's there
+    milled_raw, remaining, _ := self.library.SplitAfter(min(3, self.library.Size()))
     milled := CardMap(milled_raw)
-    self.logText(", mill ")
-    self.logCardMap(milled)
     self.library = remaining
-    for c, _ := range milled.Items() {
+    self.record(eventMill, "", milled_raw...)
+    for _, c := range milled.Items() {
         if c.IsLand() || c.IsCreature() {
             clone := self.clone()
-            clone.logText(", grab ")
-            clone.logCard(c)
             clone.hand = clone.hand.Plus(c)
+            clone.record(eventChoose, "", c)
             ret = append(ret, clone)
         }
     }
     if len(ret) == 0 {
         clone := self.clone()
-        clone.logText(", whiff")
+        clone.record(eventChoose, "")
         ret = append(ret, clone)
     }
     return ret
@@ -435,29 +485,33 @@ func (self *gameState) castAbundantHarvest() []gameState {
     for _, chooseLand := range []bool{true, false} {
         clone := self.clone()
         if chooseLand {
-            clone.logText(", choose land")
+            clone.record(eventChoose, "land")
         } else {
-            clone.logText(", choose nonland")
+            clone.record(eventChoose, "nonland")
         }
         i := 0
-        for {
+        for i < self.library.Size() {
             nextCard := self.library.Get(i)
             if (chooseLand && nextCard.IsLand()) || (!chooseLand && !nextCard.IsLand()) {
                 break
             }
             i += 1
         }
-        revealed, library := self.library.SplitAfter(i+1)
+        // If there's no such card, the whole library is revealed for nothing
+        if i == self.library.Size() {
+            revealed, library, _ := self.library.SplitAfter(i)
+            clone.library = library
+            clone.record(eventReveal, "", revealed...)
+            clone.record(eventChoose, "")
+            ret = append(ret, clone)
+            continue
+        }
+        revealed, library, _ := self.library.SplitAfter(i+1)
         keep := revealed[i]
         clone.library = library
+        clone.record(eventReveal, "", revealed...)
         clone.hand = clone.hand.Plus(keep)
-        clone.logText(", reveal")
-        for _, c := range revealed {
-            clone.logText(" ")
-            clone.logCard(c)
-        }
-        clone.logText(", grab ")
-        clone.logCard(keep)
+        clone.record(eventChoose, "", keep)
         ret = append(ret, clone)
     }
     return ret
@@ -465,13 +519,12 @@ func (self *gameState) castAbundantHarvest() []gameState {
 
 func (self *gameState) castArborealGrazer() []gameState {
     ret := []gameState{}
-    for c, _ := range self.hand.Items() {
+    for _, c := range self.hand.Items() {
         if !c.IsLand() {
             continue
         }
         clone := self.clone()
-        clone.logText(", play ")
-        clone.logCard(c)
+        clone.record(eventPutLand, "", c)
         ret = append(ret, clone.playTapped(c)...)
     }
     return ret
@@ -480,23 +533,21 @@ func (self *gameState) castArborealGrazer() []gameState {
 
 func (self *gameState) castAncientStirrings() []gameState {
     ret := []gameState{}
-    milled_raw, remaining := self.library.SplitAfter(5)
+    milled_raw, remaining, _ := self.library.SplitAfter(min(5, self.library.Size()))
     milled := CardMap(milled_raw)
     self.library = remaining
-    self.logText(", mill ")
-    self.logCardMap(milled)
-    for c, _ := range milled.Items() {
+    self.record(eventMill, "", milled_raw...)
+    for _, c := range milled.Items() {
         if c.IsColorless() {
             clone := self.clone()
-            clone.logText(", grab ")
-            clone.logCard(c)
             clone.hand = clone.hand.Plus(c)
+            clone.record(eventChoose, "", c)
             ret = append(ret, clone)
         }
     }
     if len(ret) == 0 {
         clone := self.clone()
-        clone.logText(", whiff")
+        clone.record(eventChoose, "")
         ret = append(ret, clone)
     }
     return ret
@@ -504,13 +555,13 @@ func (self *gameState) castAncientStirrings() []gameState {
 
 
 func (clone gameState) castAzusaLostButSeeking() []gameState {
-    clone.battlefield = clone.battlefield.Plus(Card("Azusa, Lost but Seeking"))
+    clone.battlefield = clone.battlefield.Plus(clone.card("Azusa, Lost but Seeking"))
     return []gameState{clone}
 }
 
 
 func (clone gameState) castDryadOfTheIlysianGrove() []gameState {
-    clone.battlefield = clone.battlefield.Plus(Card("Dryad of the Ilysian Grove"))
+    clone.battlefield = clone.battlefield.Plus(clone.card("Dryad of the Ilysian Grove"))
     clone.landPlays += 1
     return []gameState{clone}
 }
@@ -531,6 +582,22 @@ func (clone gameState) castExplore() []gameState {
 }
 
 
+func (clone gameState) castForceOfVigor() []gameState {
+    destroyed := clone.opponent.destroyHate(2)
+    clone.record(eventDestroy, strings.Join(destroyed, " and "))
+    return []gameState{clone}
+}
+
+
+func (clone gameState) castVanilla(c card) []gameState {
+    if !c.IsSpell() || c.Type() == "sorcery" || c.Type() == "instant" {
+        return []gameState{clone}
+    }
+    clone.battlefield = clone.battlefield.Plus(c)
+    return []gameState{clone}
+}
+
+
 func (clone gameState) castPrimevalTitan() []gameState {
     clone.success = true
     return []gameState{clone}
@@ -539,8 +606,8 @@ func (clone gameState) castPrimevalTitan() []gameState {
 
 func (self *gameState) castSummonersPact() []gameState {
     ret := []gameState{}
-    for c, _ := range self.library.Items() {
-        if !c.IsCreature() {
+    for _, c := range self.library.Items() {
+        if !c.IsCreature() || c.IsVanilla() {
             continue
         }
         // Don't Pact for a card we already have in hand
@@ -549,8 +616,7 @@ func (self *gameState) castSummonersPact() []gameState {
         }
         clone := self.clone()
         clone.hand = clone.hand.Plus(c)
-        clone.logText(", grab ")
-        clone.logCard(c)
+        clone.record(eventChoose, "", c)
         clone.manaDebt = clone.manaDebt.Plus(Mana("2GG"))
         // To cut down on near-identical sequences, cast immediately
         for _, state := range clone.cast(c) {
@@ -588,8 +654,8 @@ func (clone gameState) playUrzasSaga() []gameState {
 
 
 func (clone gameState) playUrzasSagaII() []gameState {
-    clone.battlefield = clone.battlefield.Minus(Card("Urza's Saga (II)"))
-    clone.battlefield = clone.battlefield.Plus(Card("Urza's Saga"))
+    clone.battlefield = clone.battlefield.Minus(clone.card("Urza's Saga (II)"))
+    clone.battlefield = clone.battlefield.Plus(clone.card("Urza's Saga"))
     return []gameState{clone}
 }
 
@@ -606,8 +672,8 @@ func (clone gameState) playWastes() []gameState {
 
 func (self *gameState) playSimicGrowthChamber() []gameState {
     ret := []gameState{}
-    nAmulets := self.battlefield.Count(Card("Amulet of Vigor"))
-    for c, _ := range self.battlefield.Items() {
+    nAmulets := self.battlefield.Count(self.card("Amulet of Vigor"))
+    for _, c := range self.battlefield.Items() {
         if !c.IsLand() {
             continue
         }
@@ -618,8 +684,7 @@ func (self *gameState) playSimicGrowthChamber() []gameState {
         clone := self.clone()
         clone.battlefield = clone.battlefield.Minus(c)
         clone.hand = clone.hand.Plus(c)
-        clone.logText(", bounce ")
-        clone.logCard(c)
+        clone.record(eventBounce, "", c)
         ret = append(ret, clone)
     }
     return ret
@@ -627,132 +692,79 @@ func (self *gameState) playSimicGrowthChamber() []gameState {
 
 
 func (clone gameState) draw(n int) []gameState {
-    popped, library := clone.library.SplitAfter(n)
+    popped, library, err := clone.library.SplitAfter(n)
+    // Drawing from an empty library loses the game. NewGame makes sure
+    // there's enough for the draw each turn, so it takes extra draws to get
+    // here.
+    if err != nil {
+        clone.giveUp("drew from an empty library")
+        return []gameState{clone}
+    }
     clone.library = library
     clone.hand = clone.hand.Plus(popped...)
-    // ing a card map already throws an extra space in there
-    clone.logText(", draw ")
-    clone.logCardMap(CardMap(popped))
+    clone.record(eventDraw, "", popped...)
     return []gameState{clone}
 }
 
 
-func (self *gameState) logManaPool() {
-    if self.verbose && self.manaPool.Total > 0 {
-        self.logText(", ")
-        self.logMana(self.manaPool)
-        self.logText(" in pool")
-    }
-}
-
-
-func (self *gameState) logBreak() {
-    self.resolveCache()
-    t := Tag("break", "", "")
-    self.jsonLog += t.ToJSON() + ","
-}
-
-
-func (self *gameState) logText(s string) {
-    self.jsonCache += s
-}
-
-
-func (self *gameState) resolveCache() {
-    if self.jsonCache != "" {
-        t := Tag("text", self.jsonCache, "")
-        self.jsonLog += t.ToJSON() + ","
-        self.jsonCache = ""
-    }
-}
-
-
-func (self *gameState) logMana(m mana) {
-    self.resolveCache()
-    self.jsonLog += m.ToJSON() + ","
-}
-
-
-func (self *gameState) logCard(c card) {
-    self.resolveCache()
-    self.jsonLog += c.ToJSON() + ","
-}
-
-
-func (self *gameState) logCardMap(cm cardMap) {
-    for c, n := range cm.Items() {
-        if n > 1 {
-            // TODO: Use the unicode multiplication symbol instead
-            self.logText(strconv.Itoa(n) + "*")
-        }
-        self.logCard(c)
-        self.logText(" ")
-    }
-    // Get rid of the trailing whitespace
-    self.jsonCache = ""
-}
-
-
-func (self *gameState) MarkDeadEnd() {
+func (self *gameState) giveUp(reason string, cards ...card) {
     if !self.deadEnd {
-        self.logBreak()
-        self.logText("giving up!")
+        self.record(eventGiveUp, reason, cards...)
         self.deadEnd = true
+        self.log.Log(context.Background(), LevelTrace, "line gave up", "turn", self.turn, "reason", reason)
     }
 }
 
 
-func (self *gameState) ToJSON() string {
-    self.resolveCache()
-    turn := "-1"
-    if self.success {
-        turn = strconv.Itoa(self.turn)
+// Every event, with zone snapshots, for clients that want to do their own
+// rendering
+func (self *gameState) events() []Event {
+    events := []Event{}
+    for _, e := range self.replay() {
+        events = append(events, e.export())
     }
-    // Pull off the last trailing comma so we have a valid JSON list of objects
-    return "{\"turn\": " + turn + ", " +
-        "\"plays\": [" + self.jsonLog[:len(self.jsonLog)-1] + "]}\n"
+    return events
 }
 
 
-type miniGame struct {
-    Turn        int     `json:"turn"`
-    OnThePlay   bool    `json:"onThePlay"`
+func (self *gameState) plays() []PlayTag {
+    return renderTags(self.replay(), self.verbose)
 }
 
 
-func (self *gameState) ToMiniJSON() string {
-    mini := miniGame{
-        Turn: self.turn,
-        OnThePlay: self.onThePlay,
+func (self *gameState) LogSize() int {
+    if self.lastEvent == nil {
+        return 0
     }
-    b, _ := json.Marshal(mini)
-    return string(b) + "\n"
+    return self.lastEvent.depth + 1
 }
 
 
-func (self *gameState) LogSize() int {
-    return len(self.jsonLog)
+func (self *gameState) Pretty() string {
+    return prettyTags(self.plays(), self.success, true)
 }
 
 
-func (self *gameState) Pretty() string {
-    return PrettyJSON(self.ToJSON())
+func (self *gameState) text() string {
+    return prettyTags(self.plays(), self.success, false)
 }
 
 
-func (state *gameState) Hash() string {
+func (state *gameState) Hash() uint64 {
     // We don't care about order for battlefield or hand, but we do care about
     // the order of the library
-    return strings.Join(
-        []string{
-            state.hand.Pretty(),
-            state.battlefield.Pretty(),
-            state.manaPool.Pretty(),
-            strconv.FormatBool(state.success),
-            strconv.FormatBool(state.deadEnd),
-            strconv.Itoa(state.landPlays),
-            state.library.Pretty(),
-        },
-        ";",
-    )
+    h := zobristCardMap(zobristHand, state.hand) ^
+        zobristCardMap(zobristBattlefield, state.battlefield) ^
+        zobristKey(zobristScalar, zobristManaGreen, uint64(state.manaPool.Green)) ^
+        zobristKey(zobristScalar, zobristManaTotal, uint64(state.manaPool.Total)) ^
+        zobristBool(zobristSuccess, state.success) ^
+        zobristBool(zobristDeadEnd, state.deadEnd) ^
+        zobristKey(zobristScalar, zobristLandPlays, uint64(state.landPlays)) ^
+        zobristKey(zobristScalar, zobristSpellsCast, uint64(state.spellsCast)) ^
+        zobristKey(zobristScalar, zobristOpponentSpent, state.opponent.spent) ^
+        zobristKey(zobristScalar, zobristOpponentDestroyed, state.opponent.destroyed)
+    for i, c := range state.library.arr {
+        h ^= zobristKey(zobristLibrary, uint64(i), uint64(c.id))
+    }
+    return h
 }
@@ -0,0 +1,140 @@
+package lib
+
+
+// Static hate pieces are opponent disruptions of kind "hate". Once they land
+// they stay on the battlefield and change how our cards work until we find a
+// sideboard answer like Force of Vigor.
+
+
+var hatePieces = map[string]bool{
+    "Blood Moon": true,
+    "Chalice of the Void": true,
+    "Damping Sphere": true,
+    "Magus of the Moon": true,
+}
+
+
+func (self *opponent) hasHate(name string) bool {
+    for i, d := range self.disruptions {
+        if d.Kind == "hate" && d.Card == name && self.inPlay(i) {
+            return true
+        }
+    }
+    return false
+}
+
+
+func (self *opponent) bloodMoon() bool {
+    return self.hasHate("Blood Moon") || self.hasHate("Magus of the Moon")
+}
+
+
+func (self *opponent) dampingSphere() bool {
+    return self.hasHate("Damping Sphere")
+}
+
+
+// Is there a Chalice of the Void in play with this many counters?
+func (self *opponent) chalice(x int) bool {
+    for i, d := range self.disruptions {
+        if d.Kind == "hate" && d.Card == "Chalice of the Void" && d.X == x && self.inPlay(i) {
+            return true
+        }
+    }
+    return false
+}
+
+
+func (self *opponent) inPlay(i int) bool {
+    return self.isSpent(i) && self.destroyed & (1 << uint(i)) == 0
+}
+
+
+// Destroy up to n artifact and enchantment hate pieces, like Force of Vigor
+// does. Return the names of whatever we hit.
+func (self *opponent) destroyHate(n int) []string {
+    ret := []string{}
+    for i, d := range self.disruptions {
+        if len(ret) >= n {
+            break
+        }
+        if d.Kind != "hate" || !self.inPlay(i) || d.Card == "Magus of the Moon" {
+            continue
+        }
+        self.destroyed |= 1 << uint(i)
+        ret = append(ret, d.Card)
+    }
+    return ret
+}
+
+
+// Under Blood Moon, nonbasic lands are Mountains. They lose all their other
+// abilities, including entering tapped and bouncing lands.
+func (self *gameState) isMountain(c card) bool {
+    return self.opponent.bloodMoon() && c.IsLand() && !c.IsBasic()
+}
+
+
+func (self *gameState) tapsFor(c card) mana {
+    if self.isMountain(c) {
+        return Mana("1")
+    }
+    m := c.TapsFor()
+    // Damping Sphere turns bounce lands into a single colorless
+    if self.opponent.dampingSphere() && m.Total > 1 {
+        return Mana("1")
+    }
+    return m
+}
+
+
+func (self *gameState) entersTapped(c card) bool {
+    if self.isMountain(c) {
+        return false
+    }
+    return c.EntersTapped()
+}
+
+
+func (self *gameState) canActivate(c card) bool {
+    if self.isMountain(c) {
+        return false
+    }
+    // Castle Garenbrig would make a single colorless under Damping Sphere
+    if self.opponent.dampingSphere() && c.Name() == "Castle Garenbrig" {
+        return false
+    }
+    return c.HasAbility()
+}
+
+
+// Damping Sphere taxes each spell after the first in a turn. Elvish Spirit
+// Guide is exiled rather than cast, so it's exempt from Sphere and Chalice.
+func (self *gameState) castingCost(c card) mana {
+    cost := c.CastingCost()
+    if self.opponent.dampingSphere() && c.IsSpell() {
+        cost = cost.Plus(mana{Total: self.spellsCast})
+    }
+    return cost
+}
+
+
+func (self *gameState) chaliceCounters(c card) bool {
+    return c.IsSpell() && self.opponent.chalice(c.CastingCost().Total)
+}
+
+
+// When Blood Moon lands, Urza's Saga loses its chapter abilities and is
+// sacrificed right away.
+func (clone gameState) sacrificeSagas() gameState {
+    if !clone.opponent.bloodMoon() {
+        return clone
+    }
+    for _, c := range []card{clone.card("Urza's Saga"), clone.card("Urza's Saga (II)")} {
+        for clone.battlefield.Count(c) > 0 {
+            clone.battlefield = clone.battlefield.Minus(c)
+            clone.record(eventSacrifice, "", c)
+        }
+    }
+    return clone
+}
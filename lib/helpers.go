@@ -2,50 +2,36 @@ package lib
 
 
 import (
-    "io/ioutil"
-    "log"
+    "log/slog"
     "math/rand"
-    "strconv"
-    "strings"
     "time"
 )
 
 
-func LoadDeck() ([]string, error) {
-    list := []string{}
-    for _, line := range readLines("decklist.txt") {
-        n_card := strings.SplitN(line, " ", 2)
-        n, err := strconv.Atoi(n_card[0])
-        if err != nil { log.Fatal(err) }
-        for i := 0; i<n; i++ {
-            list = append(list, n_card[1])
-        }
-    }
-    return Shuffled(list), nil
-}
+// Below debug, for following single lines of play through the search. A
+// game can have hundreds.
+const LevelTrace = slog.LevelDebug - 4
 
 
 func Shuffled(seq []string) []string {
-    rand.Seed(time.Now().UTC().UnixNano())
+    return ShuffledSeed(seq, NewSeed())
+}
+
+
+// The same seed always gives the same order, so a game can be replayed
+func ShuffledSeed(seq []string, seed int64) []string {
+    r := rand.New(rand.NewSource(seed))
     ret := make([]string, len(seq))
-    for i, j := range rand.Perm(len(seq)) {
+    for i, j := range r.Perm(len(seq)) {
         ret[i] = seq[j]
     }
     return ret
 }
 
 
-func readLines(filename string) []string {
-    lines := []string{}
-    text_bytes, err := ioutil.ReadFile(filename)
-    if err != nil { log.Fatal(err) }
-    for _, line := range strings.Split(string(text_bytes), "\n") {
-        // Skip empty lines and comments
-        if len(line) == 0 { continue }
-        if line[:1] == "#" { continue }
-        lines = append(lines, line)
-    }
-    return lines
+// Kept under 2^53 so it survives a round trip through JavaScript
+func NewSeed() int64 {
+    return timestamp() & (1<<53 - 1)
 }
 
 
@@ -0,0 +1,282 @@
+package lib
+
+
+import (
+    "context"
+    crand "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "runtime/debug"
+    "sync"
+    "time"
+)
+
+
+// Simulations run in the background on a fixed pool of workers. Clients
+// submit one, get back an ID, and poll for progress. Finished jobs stick
+// around so their results can be fetched, until room is needed for new ones.
+
+
+const (
+    JobQueued = "queued"
+    JobRunning = "running"
+    JobDone = "done"
+    JobCanceled = "canceled"
+    JobFailed = "failed"
+)
+
+
+var (
+    ErrUnknownJob = errors.New("no such job")
+    ErrQueueFull = errors.New("too many jobs, try again later")
+    ErrQueueClosed = errors.New("not taking jobs, shutting down")
+    ErrEnginePanic = errors.New("engine panicked")
+)
+
+
+type JobStatus struct {
+    ID string                   `json:"id"`
+    State string                `json:"state"`
+    Spec SimSpec                `json:"spec"`
+    // Games played so far, out of total
+    Done int                    `json:"done"`
+    Total int                   `json:"total"`
+    // Partial while the job is running
+    Result SimResult            `json:"result"`
+    Error string                `json:"error,omitempty"`
+    Created time.Time           `json:"created"`
+    Started *time.Time          `json:"started,omitempty"`
+    Finished *time.Time         `json:"finished,omitempty"`
+}
+
+
+func (self *JobStatus) finished() bool {
+    return self.State == JobDone || self.State == JobCanceled || self.State == JobFailed
+}
+
+
+type job struct {
+    sim *Simulation
+    ctx context.Context
+    cancel context.CancelFunc
+    // Guarded by the queue's lock
+    status JobStatus
+}
+
+
+type JobQueue struct {
+    lock sync.Mutex
+    jobs map[string]*job
+    // IDs oldest first, for deciding what to forget
+    order []string
+    pending chan *job
+    maxJobs int
+    closed bool
+    workers sync.WaitGroup
+}
+
+
+// Start workers that run up to that many jobs at once. At most maxJobs are
+// kept, counting queued, running, and finished ones.
+func NewJobQueue(workers int, maxJobs int) *JobQueue {
+    queue := &JobQueue{
+        jobs: make(map[string]*job),
+        pending: make(chan *job, maxJobs),
+        maxJobs: maxJobs,
+    }
+    for i := 0; i < workers; i++ {
+        queue.workers.Add(1)
+        go queue.work()
+    }
+    return queue
+}
+
+
+func (self *JobQueue) Submit(sim *Simulation) (JobStatus, error) {
+    b := make([]byte, 8)
+    _, err := crand.Read(b)
+    if err != nil {
+        return JobStatus{}, err
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    j := &job{
+        sim: sim,
+        ctx: ctx,
+        cancel: cancel,
+        status: JobStatus{
+            ID: hex.EncodeToString(b),
+            State: JobQueued,
+            Spec: sim.Spec,
+            Total: sim.Total(),
+            Result: SimResult{}.clone(),
+            Created: time.Now(),
+        },
+    }
+    self.lock.Lock()
+    defer self.lock.Unlock()
+    if self.closed {
+        cancel()
+        return JobStatus{}, ErrQueueClosed
+    }
+    if !self.makeRoom() {
+        cancel()
+        return JobStatus{}, ErrQueueFull
+    }
+    // Jobs canceled while queued stay in the channel until a worker skips
+    // them, so it can fill up even when there's room in the map
+    select {
+        case self.pending <- j:
+        default:
+            cancel()
+            return JobStatus{}, ErrQueueFull
+    }
+    self.jobs[j.status.ID] = j
+    self.order = append(self.order, j.status.ID)
+    return j.status, nil
+}
+
+
+// Forget the oldest finished job if we're at capacity. Call with the lock held.
+func (self *JobQueue) makeRoom() bool {
+    if len(self.jobs) < self.maxJobs {
+        return true
+    }
+    for i, id := range self.order {
+        if self.jobs[id].status.finished() {
+            delete(self.jobs, id)
+            self.order = append(self.order[:i], self.order[i+1:]...)
+            return true
+        }
+    }
+    return false
+}
+
+
+func (self *JobQueue) Get(id string) (JobStatus, error) {
+    self.lock.Lock()
+    defer self.lock.Unlock()
+    j, ok := self.jobs[id]
+    if !ok {
+        return JobStatus{}, ErrUnknownJob
+    }
+    return j.status, nil
+}
+
+
+// Stop a job. A running job gives up on the game it's on, and keeps the games
+// it already finished. Canceling a finished job does nothing.
+func (self *JobQueue) Cancel(id string) (JobStatus, error) {
+    self.lock.Lock()
+    defer self.lock.Unlock()
+    j, ok := self.jobs[id]
+    if !ok {
+        return JobStatus{}, ErrUnknownJob
+    }
+    if !j.status.finished() {
+        j.cancel()
+        if j.status.State == JobQueued {
+            self.finish(j, JobCanceled, nil)
+        }
+    }
+    return j.status, nil
+}
+
+
+// Call with the lock held
+func (self *JobQueue) finish(j *job, state string, err error) {
+    now := time.Now()
+    j.status.State = state
+    j.status.Finished = &now
+    if err != nil {
+        j.status.Error = err.Error()
+    }
+}
+
+
+func (self *JobQueue) work() {
+    defer self.workers.Done()
+    for j := range self.pending {
+        self.run(j)
+    }
+}
+
+
+// Stop taking jobs and cancel the ones still waiting. Running jobs get until
+// ctx is done to finish, then they're canceled too, partway through whatever
+// game each one is on. Returns once every worker has stopped.
+func (self *JobQueue) Shutdown(ctx context.Context) error {
+    self.lock.Lock()
+    if !self.closed {
+        self.closed = true
+        close(self.pending)
+        for _, j := range self.jobs {
+            if j.status.State == JobQueued {
+                j.cancel()
+                self.finish(j, JobCanceled, nil)
+            }
+        }
+    }
+    self.lock.Unlock()
+    done := make(chan struct{})
+    go func() {
+        self.workers.Wait()
+        close(done)
+    }()
+    select {
+        case <-done:
+            return nil
+        case <-ctx.Done():
+    }
+    self.lock.Lock()
+    for _, j := range self.jobs {
+        j.cancel()
+    }
+    self.lock.Unlock()
+    <-done
+    return ctx.Err()
+}
+
+
+func (self *JobQueue) run(j *job) {
+    self.lock.Lock()
+    // Canceled while it was waiting
+    if j.status.finished() {
+        self.lock.Unlock()
+        return
+    }
+    now := time.Now()
+    j.status.State = JobRunning
+    j.status.Started = &now
+    self.lock.Unlock()
+    result, err := j.runSafely(func(partial SimResult, done int) {
+        self.lock.Lock()
+        j.status.Result = partial
+        j.status.Done = done
+        self.lock.Unlock()
+    })
+    self.lock.Lock()
+    defer self.lock.Unlock()
+    j.status.Result = result.clone()
+    switch {
+        case errors.Is(err, context.Canceled):
+            self.finish(j, JobCanceled, nil)
+        case err != nil:
+            self.finish(j, JobFailed, err)
+        default:
+            self.finish(j, JobDone, nil)
+    }
+    j.cancel()
+}
+
+
+// A bug in the engine fails the job rather than taking down the server
+func (self *job) runSafely(progress func(SimResult, int)) (result SimResult, err error) {
+    defer func() {
+        if r := recover(); r != nil {
+            err = fmt.Errorf("%w: %v", ErrEnginePanic, r)
+            self.sim.log.Error("simulation panicked", "err", r, "stack", string(debug.Stack()))
+        }
+    }()
+    return self.sim.Run(self.ctx, progress)
+}
@@ -0,0 +1,201 @@
+package lib
+
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+)
+
+
+func testSimulation(t *testing.T, trials int) *Simulation {
+    t.Helper()
+    seed := int64(1)
+    sim, err := NewSimulation(SimSpec{Kind: SimGoldfish, Trials: trials, Seed: &seed}, DefaultBudget())
+    if err != nil {
+        t.Fatal(err)
+    }
+    return sim
+}
+
+
+// Poll until the job gets to the given state
+func waitForState(t *testing.T, queue *JobQueue, id string, state string) JobStatus {
+    t.Helper()
+    deadline := time.Now().Add(30 * time.Second)
+    for time.Now().Before(deadline) {
+        status, err := queue.Get(id)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if status.State == state {
+            return status
+        }
+        time.Sleep(time.Millisecond)
+    }
+    t.Fatalf("job %s never got to %s", id, state)
+    return JobStatus{}
+}
+
+
+func TestJobRuns(t *testing.T) {
+    queue := NewJobQueue(1, 4)
+    defer queue.Shutdown(context.Background())
+    status, err := queue.Submit(testSimulation(t, 3))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if status.State != JobQueued || status.Total != 3 || status.Started != nil {
+        t.Errorf("got %+v, want a fresh queued job", status)
+    }
+    status = waitForState(t, queue, status.ID, JobDone)
+    if status.Done != 3 || status.Result.Games.Games != 3 {
+        t.Errorf("got %d games done, %d tallied, want 3", status.Done, status.Result.Games.Games)
+    }
+    if status.Started == nil || status.Finished == nil || status.Error != "" {
+        t.Errorf("got %+v, want start and finish times and no error", status)
+    }
+    _, err = queue.Get("nope")
+    if !errors.Is(err, ErrUnknownJob) {
+        t.Errorf("got %v, want %v", err, ErrUnknownJob)
+    }
+}
+
+
+// With no workers, nothing leaves the queue
+func TestJobQueueFull(t *testing.T) {
+    queue := NewJobQueue(0, 2)
+    defer queue.Shutdown(context.Background())
+    first, err := queue.Submit(testSimulation(t, 1))
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, err = queue.Submit(testSimulation(t, 1))
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, err = queue.Submit(testSimulation(t, 1))
+    if !errors.Is(err, ErrQueueFull) {
+        t.Fatalf("got %v, want %v", err, ErrQueueFull)
+    }
+    // Canceling one makes room, and the canceled one is forgotten. It's still
+    // in the channel though, so that's full until a worker skips it.
+    status, err := queue.Cancel(first.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if status.State != JobCanceled || status.Finished == nil {
+        t.Errorf("got %+v, want a canceled job", status)
+    }
+    _, err = queue.Submit(testSimulation(t, 1))
+    if !errors.Is(err, ErrQueueFull) {
+        t.Errorf("got %v, want %v", err, ErrQueueFull)
+    }
+    _, err = queue.Get(first.ID)
+    if !errors.Is(err, ErrUnknownJob) {
+        t.Errorf("got %v, want %v", err, ErrUnknownJob)
+    }
+}
+
+
+func TestJobCancelRunning(t *testing.T) {
+    queue := NewJobQueue(1, 4)
+    defer queue.Shutdown(context.Background())
+    status, err := queue.Submit(testSimulation(t, 100000))
+    if err != nil {
+        t.Fatal(err)
+    }
+    waitForState(t, queue, status.ID, JobRunning)
+    _, err = queue.Cancel(status.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    status = waitForState(t, queue, status.ID, JobCanceled)
+    if status.Done >= status.Total || status.Result.Games.Games != status.Done {
+        t.Errorf("got %d of %d games done, %d tallied", status.Done, status.Total, status.Result.Games.Games)
+    }
+    // Canceling again does nothing
+    again, err := queue.Cancel(status.ID)
+    if err != nil || again.State != JobCanceled || again.Done != status.Done {
+        t.Errorf("got %+v, %v, want the same canceled job", again, err)
+    }
+}
+
+
+// A canceled game gives up right away rather than playing out the search
+func TestGameCanceled(t *testing.T) {
+    deck, err := LoadDecklist(DefaultDeck)
+    if err != nil {
+        t.Fatal(err)
+    }
+    budget := DefaultBudget()
+    budget.Timeout = time.Minute
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    for _, seed := range hardHandSeeds {
+        shuffled := ShuffledSeed(deck.Main, seed)
+        expanded := []int{}
+        for _, c := range []context.Context{context.Background(), ctx} {
+            game, err := NewGame(deck.Name, shuffled[7:], shuffled[:7], false, false, budget, OpponentProfile{}, seed)
+            if err != nil {
+                t.Fatal(err)
+            }
+            game.SetContext(c)
+            game, err = game.Run(SearchBreadthFirst)
+            if err != nil {
+                t.Fatal(err)
+            }
+            expanded = append(expanded, game.stats.Expanded)
+            if c.Err() != nil && (game.success || !game.stats.OutOfBudget) {
+                t.Errorf("seed %d: canceled game should give up", seed)
+            }
+        }
+        if expanded[1] >= expanded[0] {
+            t.Errorf("seed %d: canceled game expanded %d states, full game %d", seed, expanded[1], expanded[0])
+        }
+    }
+    sim := testSimulation(t, 1)
+    _, err = sim.Run(ctx, func(SimResult, int) {})
+    if !errors.Is(err, context.Canceled) {
+        t.Errorf("got %v, want %v", err, context.Canceled)
+    }
+}
+
+
+func TestJobQueueShutdown(t *testing.T) {
+    queue := NewJobQueue(1, 4)
+    running, err := queue.Submit(testSimulation(t, 100000))
+    if err != nil {
+        t.Fatal(err)
+    }
+    waitForState(t, queue, running.ID, JobRunning)
+    queued, err := queue.Submit(testSimulation(t, 1))
+    if err != nil {
+        t.Fatal(err)
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 50 * time.Millisecond)
+    defer cancel()
+    err = queue.Shutdown(ctx)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+    }
+    for _, id := range []string{running.ID, queued.ID} {
+        status, err := queue.Get(id)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if status.State != JobCanceled {
+            t.Errorf("got %s, want %s", status.State, JobCanceled)
+        }
+    }
+    _, err = queue.Submit(testSimulation(t, 1))
+    if !errors.Is(err, ErrQueueClosed) {
+        t.Errorf("got %v, want %v", err, ErrQueueClosed)
+    }
+    // A second shutdown has nothing left to wait for
+    err = queue.Shutdown(context.Background())
+    if err != nil {
+        t.Errorf("got %v, want no error", err)
+    }
+}
@@ -3,11 +3,14 @@ package lib
 
 import (
     "errors"
-    "log"
+    "fmt"
     "strconv"
 )
 
 
+var ErrInvalidManaString = errors.New("can't parse mana")
+
+
 type mana struct {
     Green int   `yaml:"green"`
     Total int   `yaml:"total"`
@@ -65,13 +68,14 @@ func (m *mana) Pretty() string {
     return s
 }
 
-func (self *mana) ToJSON() string {
-    t := Tag("mana", self.Pretty(), "")
-    return t.ToJSON()
+func (self *mana) Tag() PlayTag {
+    return Tag("mana", self.Pretty(), "")
 }
 
 
-func Mana(s string) mana {
+// Parse a cost like "2GG". Only green is tracked, so other colors should be
+// written as generic.
+func ParseMana(s string) (mana, error) {
     green := 0
     total := 0
     for _, c := range s {
@@ -81,8 +85,19 @@ func Mana(s string) mana {
         } else if '0' <= c && c <= '9' {
             total += int(c - '0')
         } else {
-            log.Fatal("failed to parse mana cost: " + s)
+            return mana{}, fmt.Errorf("%w: %q", ErrInvalidManaString, s)
         }
     }
-    return mana{Green: green, Total: total}
+    return mana{Green: green, Total: total}, nil
+}
+
+
+// For costs written into the engine, like regexp.MustCompile. A typo there
+// is a bug, not bad input, so it panics.
+func Mana(s string) mana {
+    m, err := ParseMana(s)
+    if err != nil {
+        panic(err)
+    }
+    return m
 }
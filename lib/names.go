@@ -0,0 +1,173 @@
+package lib
+
+
+import (
+    "errors"
+    "sort"
+    "strings"
+)
+
+
+// Card names come in from decklists and HTTP payloads typed by humans, so be
+// forgiving. "Summoners Pact", "summoner's pact", and "Summoner’s Pact" all
+// mean the same card, as does any alias listed in the card data.
+
+
+var ErrUnknownCard = errors.New("no data for card")
+
+
+type UnknownCardError struct {
+    Name string
+    Suggestions []string
+}
+
+
+func (self *UnknownCardError) Error() string {
+    text := "no data for: " + self.Name
+    if len(self.Suggestions) > 0 {
+        text += " (did you mean " + strings.Join(self.Suggestions, ", ") + "?)"
+    }
+    return text
+}
+
+
+func (self *UnknownCardError) Is(target error) bool {
+    return target == ErrUnknownCard
+}
+
+
+// Map from normalized name to the name used in the card data. A card's own
+// name always wins over another card's alias or front face. Between two
+// aliases, the card that sorts first wins, so it doesn't come down to map
+// order.
+func buildNameIndex(cards map[string]cardData) map[string]string {
+    index := make(map[string]string)
+    names := []string{}
+    for name := range cards {
+        index[normalizeName(name)] = name
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        others := cards[name].Aliases
+        // Split cards and double-faced cards can go by their front face
+        if i := strings.Index(name, " // "); i > 0 {
+            others = append([]string{name[:i]}, others...)
+        }
+        for _, other := range others {
+            if _, ok := index[normalizeName(other)]; !ok {
+                index[normalizeName(other)] = name
+            }
+        }
+    }
+    return index
+}
+
+
+var diacritics = strings.NewReplacer(
+    "á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+    "é", "e", "è", "e", "ê", "e", "ë", "e",
+    "í", "i", "ì", "i", "î", "i", "ï", "i",
+    "ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o",
+    "ú", "u", "ù", "u", "û", "u", "ü", "u",
+    "ñ", "n", "ç", "c", "æ", "ae",
+)
+
+
+func normalizeName(name string) string {
+    name = diacritics.Replace(strings.ToLower(name))
+    for _, c := range []string{"'", "’", "‘", ",", "."} {
+        name = strings.ReplaceAll(name, c, "")
+    }
+    // Collapse runs of whitespace, and trim the ends
+    return strings.Join(strings.Fields(name), " ")
+}
+
+
+// Find the card data name for something a person typed
+func ResolveCardName(name string) (string, error) {
+    cds := cardDataCache()
+    if _, ok := cds.cards[name]; ok {
+        return name, nil
+    }
+    if resolved, ok := cds.names[normalizeName(name)]; ok {
+        return resolved, nil
+    }
+    return "", &UnknownCardError{Name: name, Suggestions: suggestCardNames(name)}
+}
+
+
+func ResolveCardNames(names []string) ([]string, error) {
+    ret := make([]string, len(names))
+    // Decklists repeat names a lot, so only resolve each one once
+    cache := make(map[string]string)
+    for i, name := range names {
+        resolved, ok := cache[name]
+        if !ok {
+            var err error
+            resolved, err = ResolveCardName(name)
+            if err != nil {
+                return []string{}, err
+            }
+            cache[name] = resolved
+        }
+        ret[i] = resolved
+    }
+    return ret, nil
+}
+
+
+// Up to three card names close to the given one, closest first
+func suggestCardNames(name string) []string {
+    type candidate struct {
+        name string
+        distance int
+    }
+    target := normalizeName(name)
+    // Allow about one typo per four letters
+    maxDistance := len(target)/4 + 1
+    candidates := []candidate{}
+    seen := make(map[string]bool)
+    for key, resolved := range cardDataCache().names {
+        d := editDistance(target, key)
+        if d > maxDistance || seen[resolved] {
+            continue
+        }
+        seen[resolved] = true
+        candidates = append(candidates, candidate{name: resolved, distance: d})
+    }
+    sort.Slice(candidates, func(i, j int) bool {
+        if candidates[i].distance != candidates[j].distance {
+            return candidates[i].distance < candidates[j].distance
+        }
+        return candidates[i].name < candidates[j].name
+    })
+    ret := []string{}
+    for i := 0; i < len(candidates) && i < 3; i++ {
+        ret = append(ret, candidates[i].name)
+    }
+    return ret
+}
+
+
+// Levenshtein distance, counting runes rather than bytes
+func editDistance(a string, b string) int {
+    ra, rb := []rune(a), []rune(b)
+    prev := make([]int, len(rb)+1)
+    curr := make([]int, len(rb)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+    for i := 1; i <= len(ra); i++ {
+        curr[0] = i
+        for j := 1; j <= len(rb); j++ {
+            cost := 1
+            if ra[i-1] == rb[j-1] {
+                cost = 0
+            }
+            curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+        }
+        prev, curr = curr, prev
+    }
+    return prev[len(rb)]
+}
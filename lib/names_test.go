@@ -0,0 +1,129 @@
+package lib
+
+
+import (
+    "errors"
+    "slices"
+    "testing"
+)
+
+
+func TestResolveCardName(t *testing.T) {
+    cases := []struct {
+        typed string
+        want string
+    }{
+        {"Summoner's Pact", "Summoner's Pact"},
+        {"summoner's pact", "Summoner's Pact"},
+        {"Summoners Pact", "Summoner's Pact"},
+        {"Summoner’s Pact", "Summoner's Pact"},
+        {"  SUMMONER'S   PACT ", "Summoner's Pact"},
+        {"Azusa Lost but Seeking", "Azusa, Lost but Seeking"},
+        {"Valakut, the Molten Pinnacle", "Valakut, the Molten Pinnacle"},
+        {"Pact", "Summoner's Pact"},
+        {"titan", "Primeval Titan"},
+        {"Prime Time", "Primeval Titan"},
+        {"Tormód's Crypt", "Tormod's Crypt"},
+    }
+    for _, tc := range cases {
+        got, err := ResolveCardName(tc.typed)
+        if err != nil {
+            t.Errorf("%q: %v", tc.typed, err)
+            continue
+        }
+        if got != tc.want {
+            t.Errorf("%q: got %q, want %q", tc.typed, got, tc.want)
+        }
+    }
+}
+
+
+func TestUnknownCardSuggestions(t *testing.T) {
+    cases := []struct {
+        typed string
+        want []string
+    }{
+        {"Primevil Titan", []string{"Primeval Titan"}},
+        {"Forrest", []string{"Forest"}},
+        {"Wates", []string{"Wastes"}},
+        // Close to more than one card, so the closest comes first
+        {"Urzas Saga (I)", []string{"Urza's Saga (II)", "Urza's Saga"}},
+        {"Explor", []string{"Explore"}},
+        {"Black Lotus", []string{}},
+    }
+    for _, tc := range cases {
+        _, err := ResolveCardName(tc.typed)
+        unknown := &UnknownCardError{}
+        if !errors.As(err, &unknown) || !errors.Is(err, ErrUnknownCard) {
+            t.Errorf("%q: got %v, want an unknown card error", tc.typed, err)
+            continue
+        }
+        if !slices.Equal(unknown.Suggestions, tc.want) {
+            t.Errorf("%q: got suggestions %q, want %q", tc.typed, unknown.Suggestions, tc.want)
+        }
+    }
+}
+
+
+// When names collide after normalizing, the answer can't depend on map order
+func TestBuildNameIndexAmbiguous(t *testing.T) {
+    cards := map[string]cardData{
+        "Dryad": {Name: "Dryad"},
+        "Dryad of the Ilysian Grove": {Name: "Dryad of the Ilysian Grove", Aliases: []string{"Dryad", "Grove"}},
+        "Boseiju, Who Endures // Boseiju": {Name: "Boseiju, Who Endures // Boseiju"},
+        "Zebra Grove": {Name: "Zebra Grove", Aliases: []string{"Grove"}},
+        "Another Grove": {Name: "Another Grove", Aliases: []string{"grove"}},
+    }
+    want := map[string]string{
+        // A card's own name beats another card's alias
+        "dryad": "Dryad",
+        "dryad of the ilysian grove": "Dryad of the Ilysian Grove",
+        // Front faces work too
+        "boseiju who endures": "Boseiju, Who Endures // Boseiju",
+        // Two aliases: first by name
+        "grove": "Another Grove",
+    }
+    for i := 0; i < 20; i++ {
+        index := buildNameIndex(cards)
+        for key, name := range want {
+            if index[key] != name {
+                t.Fatalf("%q: got %q, want %q", key, index[key], name)
+            }
+        }
+    }
+}
+
+
+func TestEditDistance(t *testing.T) {
+    cases := []struct {
+        a, b string
+        want int
+    }{
+        {"", "", 0},
+        {"forest", "", 6},
+        {"forest", "forest", 0},
+        {"forest", "forrest", 1},
+        {"forest", "froest", 2},
+        {"tormod", "tormöd", 1},
+    }
+    for _, tc := range cases {
+        if got := editDistance(tc.a, tc.b); got != tc.want {
+            t.Errorf("%q, %q: got %d, want %d", tc.a, tc.b, got, tc.want)
+        }
+    }
+}
+
+
+func TestResolveCardNames(t *testing.T) {
+    got, err := ResolveCardNames([]string{"titan", "Forest", "titan"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !slices.Equal(got, []string{"Primeval Titan", "Forest", "Primeval Titan"}) {
+        t.Errorf("got %q", got)
+    }
+    _, err = ResolveCardNames([]string{"Forest", "Black Lotus"})
+    if !errors.Is(err, ErrUnknownCard) {
+        t.Errorf("got %v, want %v", err, ErrUnknownCard)
+    }
+}
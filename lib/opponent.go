@@ -0,0 +1,261 @@
+package lib
+
+
+import (
+    "errors"
+    "fmt"
+    "gopkg.in/yaml.v2"
+    "math/rand"
+    "sort"
+    "strconv"
+)
+
+
+// An opponent profile describes the interaction we expect to face. Each
+// disruption is rolled once at the start of the game, so a given game either
+// has the Thoughtseize or it doesn't, and the search plays around whatever the
+// opponent actually drew.
+type OpponentProfile struct {
+    Name string                 `json:"name" yaml:"name"`
+    Disruptions []Disruption    `json:"disruptions" yaml:"disruptions"`
+}
+
+
+type Disruption struct {
+    // One of "discard", "counter", "removal", or "hate"
+    Kind string                 `json:"kind" yaml:"kind"`
+    // Name of the opponent's card, for the play log
+    Card string                 `json:"card" yaml:"card"`
+    // Opponent's turn on which discard, removal, or a hate piece happens.
+    // Removal with no legal target is held until a later turn.
+    Turn int                    `json:"turn" yaml:"turn"`
+    // Cards it can hit, in order of preference. If empty, take the best card
+    // available.
+    Targets []string            `json:"targets" yaml:"targets"`
+    // Chance that the opponent has this card. Omitted means always.
+    Probability float64         `json:"probability" yaml:"probability"`
+    // Number of counters, for Chalice of the Void
+    X int                       `json:"x" yaml:"x"`
+    // Sideboard cards only show up in games 2 and 3
+    Sideboard bool              `json:"sideboard" yaml:"sideboard"`
+}
+
+
+// The opponent as seen from within a game. The disruptions slice is shared
+// between all states of a game and never modified. We track which pieces have
+// been used up (or for hate pieces, are on the battlefield) with a bitmask so
+// that cloning a state stays cheap.
+type opponent struct {
+    disruptions []Disruption
+    spent uint64
+    destroyed uint64
+}
+
+
+// Rolls for each disruption come from r, so the same seed always gives the
+// same opponent
+func Opponent(profile OpponentProfile, r *rand.Rand) (opponent, error) {
+    opp := opponent{}
+    if len(profile.Disruptions) > 64 {
+        return opp, errors.New("too many disruptions in opponent profile")
+    }
+    for _, d := range profile.Disruptions {
+        switch d.Kind {
+            case "discard", "counter", "removal":
+            case "hate":
+                if !hatePieces[d.Card] {
+                    return opp, errors.New("unknown hate piece: " + d.Card)
+                }
+            default:
+                return opp, errors.New("unknown disruption kind: " + d.Kind)
+        }
+        // Resolving makes a new slice, so the caller's profile is untouched
+        targets, err := ResolveCardNames(d.Targets)
+        if err != nil {
+            return opp, err
+        }
+        d.Targets = targets
+        if d.Probability > 0 && r.Float64() >= d.Probability {
+            continue
+        }
+        if d.Card == "" {
+            d.Card = "opponent " + d.Kind
+        }
+        opp.disruptions = append(opp.disruptions, d)
+    }
+    return opp, nil
+}
+
+
+var ErrSideboardOnly = errors.New("opponent only has sideboard cards, so game 1 has no disruption")
+
+
+// Drop sideboard cards from the profile for game 1. Games 2 and 3 get the whole
+// thing. A profile with nothing but sideboard cards, like blood-moon, would do
+// nothing pre-board, so it's taken as post-board when the game isn't given and
+// refused for an explicit game 1.
+func (self *OpponentProfile) ForGame(game int) (OpponentProfile, error) {
+    if game > 1 {
+        return *self, nil
+    }
+    ret := OpponentProfile{Name: self.Name}
+    for _, d := range self.Disruptions {
+        if !d.Sideboard {
+            ret.Disruptions = append(ret.Disruptions, d)
+        }
+    }
+    if len(ret.Disruptions) == 0 && len(self.Disruptions) > 0 {
+        if game == 0 {
+            return *self, nil
+        }
+        return ret, fmt.Errorf("%w: %s", ErrSideboardOnly, self.Name)
+    }
+    return ret, nil
+}
+
+
+func LoadOpponent(name string) (OpponentProfile, error) {
+    profiles := []OpponentProfile{}
+    textBytes, err := readDataFile("opponents.yaml")
+    if err != nil {
+        return OpponentProfile{}, err
+    }
+    err = yaml.Unmarshal(textBytes, &profiles)
+    if err != nil {
+        return OpponentProfile{}, err
+    }
+    for _, profile := range profiles {
+        if profile.Name == name {
+            return profile, nil
+        }
+    }
+    return OpponentProfile{}, errors.New("no opponent profile: " + name)
+}
+
+
+func (self *opponent) isSpent(i int) bool {
+    return self.spent & (1 << uint(i)) != 0
+}
+
+
+func (self *opponent) spend(i int) {
+    self.spent |= 1 << uint(i)
+}
+
+
+// Return the index of an unused counterspell that can hit this card, or -1
+func (self *opponent) counterFor(c card) int {
+    for i, d := range self.disruptions {
+        if d.Kind != "counter" || self.isSpent(i) {
+            continue
+        }
+        if len(d.Targets) == 0 && c.Name() == "Primeval Titan" {
+            return i
+        }
+        for _, target := range d.Targets {
+            if target == c.Name() {
+                return i
+            }
+        }
+    }
+    return -1
+}
+
+
+// Pick what the opponent takes from a zone. Named targets win in order, then
+// fall back on our own sense of which nonland card matters most.
+func pickTarget(cards *cardLayer, cm cardMap, targets []string) (card, bool) {
+    if len(targets) > 0 {
+        for _, target := range targets {
+            if c := cards.card(target); cm.Count(c) > 0 {
+                return c, true
+            }
+        }
+        return card{}, false
+    }
+    candidates := []card{}
+    for _, c := range cm.Items() {
+        if !c.IsLand() {
+            candidates = append(candidates, c)
+        }
+    }
+    if len(candidates) == 0 {
+        return card{}, false
+    }
+    sort.Slice(candidates, func(i, j int) bool {
+        ci, cj := candidates[i], candidates[j]
+        if ci.threatLevel() != cj.threatLevel() {
+            return ci.threatLevel() > cj.threatLevel()
+        }
+        return ci.Name() < cj.Name()
+    })
+    return candidates[0], true
+}
+
+
+// How much the opponent wants to get rid of a card. Titan is the whole game,
+// then anything that finds Titan, then anything that costs more.
+func (self *card) threatLevel() int {
+    level := self.CastingCost().Total
+    if self.CanBeTitan() {
+        level += 10
+    }
+    if self.Name() == "Primeval Titan" {
+        level += 100
+    }
+    if self.Name() == "Amulet of Vigor" {
+        level += 10
+    }
+    return level
+}
+
+
+// The opponent takes their turn. Called from passTurn before we untap.
+func (clone gameState) opponentTurn() gameState {
+    oppTurn := clone.turn
+    if clone.onThePlay {
+        oppTurn -= 1
+    }
+    for i, d := range clone.opponent.disruptions {
+        if clone.opponent.isSpent(i) {
+            continue
+        }
+        switch d.Kind {
+            case "discard":
+                if d.Turn != oppTurn {
+                    continue
+                }
+                clone.opponent.spend(i)
+                c, ok := pickTarget(clone.cards, clone.hand, d.Targets)
+                if !ok {
+                    clone.record(eventOpponentDiscard, d.Card)
+                    continue
+                }
+                clone.hand = clone.hand.Minus(c)
+                clone.record(eventOpponentDiscard, d.Card, c)
+            case "removal":
+                if d.Turn > oppTurn {
+                    continue
+                }
+                c, ok := pickTarget(clone.cards, clone.battlefield, d.Targets)
+                if !ok {
+                    continue
+                }
+                clone.opponent.spend(i)
+                clone.battlefield = clone.battlefield.Minus(c)
+                clone.record(eventOpponentRemoval, d.Card, c)
+            case "hate":
+                if d.Turn > oppTurn {
+                    continue
+                }
+                clone.opponent.spend(i)
+                if d.Card == "Chalice of the Void" {
+                    clone.record(eventOpponentHate, d.Card + " on " + strconv.Itoa(d.X))
+                } else {
+                    clone.record(eventOpponentHate, d.Card)
+                }
+                clone = clone.sacrificeSagas()
+        }
+    }
+    return clone
+}
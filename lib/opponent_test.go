@@ -0,0 +1,39 @@
+package lib
+
+
+import (
+    "testing"
+)
+
+
+// A game has to be replayable from its seed, opponent and all
+func TestOpponentSeeded(t *testing.T) {
+    profile, err := LoadOpponent("rakdos-scam")
+    if err != nil {
+        t.Fatal(err)
+    }
+    counts := map[int]bool{}
+    for seed := int64(0); seed < 50; seed++ {
+        a, err := Opponent(profile, opponentRand(seed))
+        if err != nil {
+            t.Fatal(err)
+        }
+        b, err := Opponent(profile, opponentRand(seed))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(a.disruptions) != len(b.disruptions) {
+            t.Fatalf("seed %d: got %d disruptions, then %d", seed, len(a.disruptions), len(b.disruptions))
+        }
+        for i := range a.disruptions {
+            if a.disruptions[i].Card != b.disruptions[i].Card {
+                t.Fatalf("seed %d: got %s, then %s", seed, a.disruptions[i].Card, b.disruptions[i].Card)
+            }
+        }
+        counts[len(a.disruptions)] = true
+    }
+    // Both cards are maybes, so some seeds should differ
+    if len(counts) < 2 {
+        t.Errorf("every seed rolled the same number of disruptions: %v", counts)
+    }
+}
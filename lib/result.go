@@ -0,0 +1,73 @@
+package lib
+
+
+import (
+    "errors"
+    "fmt"
+)
+
+
+// How a finished game is reported back to clients. The play log comes in one
+// of a few formats: tags for the web client to lay out, the full event log
+// with zone snapshots, or plain text.
+const (
+    FormatTags = "tags"
+    FormatEvents = "events"
+    FormatText = "text"
+    // Just the outcome, no play log
+    FormatNone = "none"
+)
+
+
+var ErrUnknownFormat = errors.New("no such format")
+
+
+type GameResult struct {
+    Success bool        `json:"success"`
+    // Turn Titan was cast, or -1 if it never was
+    Turn int            `json:"turn"`
+    OnThePlay bool      `json:"onThePlay"`
+    // Seed the library was shuffled with, to replay the same game. Set by
+    // whoever did the shuffling.
+    Seed int64          `json:"seed"`
+    Stats SearchStats   `json:"stats"`
+    Plays []PlayTag     `json:"plays,omitempty"`
+    Events []Event      `json:"events,omitempty"`
+    Text string         `json:"text,omitempty"`
+}
+
+
+func CheckFormat(format string) error {
+    switch format {
+        case "", FormatTags, FormatEvents, FormatText, FormatNone:
+            return nil
+    }
+    return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+
+// Summarize a game that has been run to the end. An empty format means tags.
+func (self *gameManager) Result(format string) (GameResult, error) {
+    ret := GameResult{Turn: -1, Stats: *self.stats}
+    // By the end of a game there's only one state left
+    for _, state := range self.states {
+        ret.Success = state.success
+        ret.OnThePlay = state.onThePlay
+        if state.success {
+            ret.Turn = state.turn
+        }
+        switch format {
+            case "", FormatTags:
+                ret.Plays = state.plays()
+            case FormatEvents:
+                ret.Events = state.events()
+            case FormatText:
+                ret.Text = state.text()
+            case FormatNone:
+            default:
+                return ret, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+        }
+        break
+    }
+    return ret, nil
+}
@@ -0,0 +1,190 @@
+package lib
+
+
+import (
+    "bufio"
+    "bytes"
+    "gopkg.in/yaml.v2"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+
+// Card data is checked strictly when it's loaded. A typo like enter_tapped
+// would otherwise be ignored and show up later as a wrong simulation.
+
+
+type CardDataError struct {
+    File string
+    Line int
+    Message string
+}
+
+
+func (self *CardDataError) Error() string {
+    if self.Line > 0 {
+        return self.File + ":" + strconv.Itoa(self.Line) + ": " + self.Message
+    }
+    return self.File + ": " + self.Message
+}
+
+
+type CardDataErrors []*CardDataError
+
+
+func (self CardDataErrors) Error() string {
+    lines := []string{}
+    for _, err := range self {
+        lines = append(lines, err.Error())
+    }
+    return strings.Join(lines, "\n")
+}
+
+
+var knownCardTypes = map[string]bool{
+    "artifact": true,
+    "creature": true,
+    "enchantment": true,
+    "instant": true,
+    "land": true,
+    "planeswalker": true,
+    "sorcery": true,
+}
+
+
+// The YAML library puts line numbers at the front of its messages
+var yamlLinePattern = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+
+// Where each card was defined, for error messages that span files
+type cardSource struct {
+    file string
+    line int
+}
+
+
+// Decode one card data file, strictly, and check each entry on its own terms.
+// Checks that need every file loaded, like targets, happen in checkCardTargets.
+func decodeCardData(filename string, textBytes []byte, sources map[string]cardSource) ([]cardData, CardDataErrors) {
+    problems := CardDataErrors{}
+    cardDataRaw := []cardData{}
+    // Strict decoding rejects unknown and duplicate keys. Its messages already
+    // say which line, so pass them through as-is.
+    err := yaml.UnmarshalStrict(textBytes, &cardDataRaw)
+    if typeErr, ok := err.(*yaml.TypeError); ok {
+        // Unknown keys don't stop the decoding, so carry on and check the
+        // rest of the file too
+        for _, msg := range typeErr.Errors {
+            problem := &CardDataError{File: filename, Message: msg}
+            if match := yamlLinePattern.FindStringSubmatch(msg); match != nil {
+                problem.Line, _ = strconv.Atoi(match[1])
+                problem.Message = match[2]
+            }
+            problems = append(problems, problem)
+        }
+    } else if err != nil {
+        return cardDataRaw, CardDataErrors{&CardDataError{File: filename, Message: err.Error()}}
+    }
+    // Decode again loosely to see which keys are present at all, since
+    // omitting casting_cost isn't the same as a cost of zero
+    present := []map[string]interface{}{}
+    yaml.Unmarshal(textBytes, &present)
+    lines := entryLines(textBytes)
+    for i, cd := range cardDataRaw {
+        line := 0
+        if i < len(lines) {
+            line = lines[i]
+        }
+        fail := func(msg string) {
+            problems = append(problems, &CardDataError{File: filename, Line: line, Message: msg})
+        }
+        if cd.Name == "" {
+            fail("entry has no name")
+            continue
+        }
+        if prev, ok := sources[cd.Name]; ok && prev.file == filename {
+            fail(cd.Name + " is already defined on line " + strconv.Itoa(prev.line))
+        }
+        sources[cd.Name] = cardSource{file: filename, line: line}
+        if !knownCardTypes[cd.Type] {
+            fail(cd.Name + " has unknown type \"" + cd.Type + "\"")
+        }
+        for field, m := range map[string]mana{
+            "activation_cost": cd.ActivationCost,
+            "casting_cost": cd.CastingCost,
+            "taps_for": cd.TapsFor,
+        } {
+            if m.Green < 0 || m.Total < 0 {
+                fail(cd.Name + " has negative " + field)
+            }
+            if m.Green > m.Total {
+                fail(cd.Name + " has more green than total in " + field)
+            }
+        }
+        if cd.Type == "land" {
+            if cd.TapsFor.Total == 0 {
+                fail(cd.Name + " is a land but doesn't tap for mana")
+            }
+            if cd.CastingCost.Total > 0 {
+                fail(cd.Name + " is a land but has a casting cost")
+            }
+        } else if i < len(present) {
+            if _, ok := present[i]["casting_cost"]; !ok {
+                fail(cd.Name + " is a " + cd.Type + " but has no casting_cost")
+            }
+        }
+        if cd.Basic && cd.Type != "land" {
+            fail(cd.Name + " is basic but not a land")
+        }
+    }
+    return cardDataRaw, problems
+}
+
+
+// Top-level list items, "- name: Forest" or a lone "-". Not "---", which
+// marks the start of a document.
+var entryLinePattern = regexp.MustCompile(`^-(\s|$)`)
+
+
+// Line number where each entry starts, in order. Entries are the items of the
+// top-level list, so they're the lines that start with a dash and a space.
+func entryLines(textBytes []byte) []int {
+    lines := []int{}
+    scanner := bufio.NewScanner(bytes.NewReader(textBytes))
+    n := 0
+    for scanner.Scan() {
+        n += 1
+        if entryLinePattern.MatchString(scanner.Text()) {
+            lines = append(lines, n)
+        }
+    }
+    return lines
+}
+
+
+func checkCardTargets(cards map[string]cardData, sources map[string]cardSource) CardDataErrors {
+    problems := CardDataErrors{}
+    for name, cd := range cards {
+        if cd.Target == "" {
+            continue
+        }
+        if _, ok := cards[cd.Target]; !ok {
+            src := sources[name]
+            problems = append(problems, &CardDataError{
+                File: src.file,
+                Line: src.line,
+                Message: name + " has target " + cd.Target + ", which has no card data",
+            })
+        }
+    }
+    return problems
+}
+
+
+// Check all card data without loading it. Returns every problem found, not
+// just the first.
+func ValidateCardData() CardDataErrors {
+    _, problems := loadCardDataSet()
+    return problems
+}
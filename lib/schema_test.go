@@ -0,0 +1,74 @@
+package lib
+
+
+import (
+    "strings"
+    "testing"
+)
+
+
+func TestDecodeCardData(t *testing.T) {
+    cases := []struct {
+        name string
+        text string
+        // Each problem as file:line: and a bit of the message
+        want []string
+    }{
+        {
+            name: "fine",
+            text: "- name: Forest\n  type: land\n  taps_for:\n    green: 1\n    total: 1\n",
+        },
+        {
+            name: "unknown field",
+            text: "- name: Forest\n  type: land\n  taps_for:\n    green: 1\n    total: 1\n  enter_tapped: true\n",
+            want: []string{"test.yaml:6: ", "enter_tapped"},
+        },
+        {
+            name: "wrong type",
+            text: "- name: Explore\n  type: sorcery\n  casting_cost:\n    green: one\n    total: 2\n",
+            want: []string{"test.yaml:4: ", "cannot unmarshal"},
+        },
+        {
+            name: "document marker",
+            text: "---\n# Lands\n- name: Forest\n  type: land\n  taps_for:\n    green: 1\n    total: 1\n-\n  name: Wastes\n  type: land\n",
+            want: []string{"test.yaml:8: ", "doesn't tap for mana"},
+        },
+        {
+            name: "duplicate",
+            text: "- name: Forest\n  type: land\n  taps_for:\n    total: 1\n- name: Forest\n  type: land\n  taps_for:\n    total: 1\n",
+            want: []string{"test.yaml:5: ", "already defined on line 1"},
+        },
+        {
+            name: "spell with no cost",
+            text: "- name: Explore\n  type: sorcery\n",
+            want: []string{"test.yaml:1: ", "no casting_cost"},
+        },
+    }
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            _, problems := decodeCardData("test.yaml", []byte(tc.text), map[string]cardSource{})
+            if len(tc.want) == 0 {
+                if len(problems) > 0 {
+                    t.Errorf("got %v, want no problems", problems)
+                }
+                return
+            }
+            if len(problems) != 1 {
+                t.Fatalf("got %d problems, want 1: %v", len(problems), problems)
+            }
+            msg := problems[0].Error()
+            if !strings.HasPrefix(msg, tc.want[0]) || !strings.Contains(msg, tc.want[1]) {
+                t.Errorf("got %q, want %q with %q", msg, tc.want[0], tc.want[1])
+            }
+        })
+    }
+}
+
+
+func TestEntryLines(t *testing.T) {
+    text := "---\n# Lands\n- name: Forest\n  aliases:\n  - Snow-Covered Forest\n-\n  name: Wastes\n"
+    got := entryLines([]byte(text))
+    if len(got) != 2 || got[0] != 3 || got[1] != 6 {
+        t.Errorf("got %v, want [3 6]", got)
+    }
+}
@@ -0,0 +1,295 @@
+package lib
+
+
+import (
+    "encoding/json"
+    "errors"
+    "gopkg.in/yaml.v2"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+
+// Fill in card data from a Scryfall bulk data file, like oracle-cards.json
+// from https://scryfall.com/docs/api/bulk-data. Everything Scryfall knows
+// about (casting cost, type, entering tapped, mana abilities) can be derived.
+// Behavior annotations like can_be_titan still need to be written by hand.
+
+
+type scryfallCard struct {
+    ID string                   `json:"id"`
+    Name string                 `json:"name"`
+    ManaCost string             `json:"mana_cost"`
+    TypeLine string             `json:"type_line"`
+    OracleText string           `json:"oracle_text"`
+    // Split cards and double-faced cards have their details on the faces
+    CardFaces []scryfallCard    `json:"card_faces"`
+}
+
+
+type ScryfallReport struct {
+    Added []string      `json:"added"`
+    Updated []string    `json:"updated"`
+    // Fields where the hand-written card data disagrees with Scryfall
+    Conflicts []string  `json:"conflicts"`
+    // Cards we wanted but Scryfall doesn't have, like Urza's Saga (II)
+    Missing []string    `json:"missing"`
+    // Decklists that couldn't be read, so their cards weren't looked for
+    Unreadable []string `json:"unreadable"`
+}
+
+
+var (
+    manaSymbolPattern = regexp.MustCompile(`\{([^}]+)\}`)
+    tapsForPattern = regexp.MustCompile(`\{T\}: Add ((?:\{[^}]+\})+|one mana of any color)`)
+    entersTappedPattern = regexp.MustCompile(`enters( the battlefield)? tapped`)
+)
+
+
+// Read the card data file at cardDataPath and update it from the Scryfall bulk
+// file at bulkPath. Entries are made for every card already in the file plus
+// every card in every decklist. Hand-written values win over Scryfall unless
+// overwrite is set; either way, disagreements are reported. Returns the new
+// card data as YAML. Comments from the original file don't survive.
+func ImportScryfall(bulkPath string, cardDataPath string, overwrite bool) ([]byte, ScryfallReport, error) {
+    report := ScryfallReport{}
+    existing := []cardData{}
+    textBytes, err := ioutil.ReadFile(cardDataPath)
+    if err != nil {
+        return nil, report, err
+    }
+    err = yaml.Unmarshal(textBytes, &existing)
+    if err != nil {
+        return nil, report, err
+    }
+    wanted := make(map[string]bool)
+    for _, cd := range existing {
+        wanted[cd.Name] = true
+    }
+    names, unreadable := deckCardNames()
+    report.Unreadable = unreadable
+    for _, name := range names {
+        wanted[name] = true
+    }
+    found, err := readScryfallBulk(bulkPath, wanted)
+    if err != nil {
+        return nil, report, err
+    }
+    ret := []cardData{}
+    for _, cd := range existing {
+        sc, ok := found[cd.Name]
+        if !ok {
+            // Made-up entries like Urza's Saga (II) point at a real card
+            if cd.Target == "" {
+                report.Missing = append(report.Missing, cd.Name)
+            }
+            ret = append(ret, cd)
+            continue
+        }
+        merged, conflicts := mergeScryfall(cd, sc, overwrite)
+        report.Conflicts = append(report.Conflicts, conflicts...)
+        if !reflect.DeepEqual(merged, cd) {
+            report.Updated = append(report.Updated, cd.Name)
+        }
+        ret = append(ret, merged)
+        delete(wanted, cd.Name)
+    }
+    // Anything left over is in a decklist but not the card data yet
+    newNames := []string{}
+    for name, _ := range wanted {
+        newNames = append(newNames, name)
+    }
+    sort.Strings(newNames)
+    for _, name := range newNames {
+        sc, ok := found[name]
+        if !ok {
+            if !containsName(existing, name) {
+                report.Missing = append(report.Missing, name)
+            }
+            continue
+        }
+        if containsName(existing, name) {
+            continue
+        }
+        merged, _ := mergeScryfall(cardData{Name: name}, sc, true)
+        ret = append(ret, merged)
+        report.Added = append(report.Added, name)
+    }
+    out, err := yaml.Marshal(ret)
+    return out, report, err
+}
+
+
+func containsName(cds []cardData, name string) bool {
+    for _, cd := range cds {
+        if cd.Name == name {
+            return true
+        }
+    }
+    return false
+}
+
+
+// Every card named in any decklist, main deck or sideboard. The point is to
+// find cards that don't have data yet, so names are taken as written unless
+// they match card data we already have. Decks that can't be read at all are
+// returned with why.
+func deckCardNames() ([]string, []string) {
+    names := []string{}
+    unreadable := []string{}
+    decks, err := ListDecks()
+    if err != nil {
+        return names, []string{err.Error()}
+    }
+    for _, deckName := range decks {
+        deck, problems, err := readDecklist(deckName)
+        if err == nil && len(problems) > 0 {
+            err = errors.New(problems[0])
+        }
+        if err != nil {
+            unreadable = append(unreadable, deckName + ": " + err.Error())
+            continue
+        }
+        for _, name := range append(deck.Main, deck.Sideboard...) {
+            if resolved, err := ResolveCardName(name); err == nil {
+                name = resolved
+            }
+            names = append(names, name)
+        }
+    }
+    return names, unreadable
+}
+
+
+// The bulk file is a single enormous JSON array. Stream through it rather than
+// holding the whole thing in memory, and only keep the cards we care about.
+func readScryfallBulk(bulkPath string, wanted map[string]bool) (map[string]scryfallCard, error) {
+    found := make(map[string]scryfallCard)
+    f, err := os.Open(filepath.Clean(bulkPath))
+    if err != nil {
+        return found, err
+    }
+    defer f.Close()
+    dec := json.NewDecoder(f)
+    tok, err := dec.Token()
+    if err != nil {
+        return found, err
+    }
+    if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+        return found, errors.New("expected a JSON array in " + bulkPath)
+    }
+    for dec.More() {
+        sc := scryfallCard{}
+        err = dec.Decode(&sc)
+        if err != nil {
+            return found, err
+        }
+        if wanted[sc.Name] {
+            found[sc.Name] = sc
+            continue
+        }
+        // Decklists tend to name just the front face of a double-faced card
+        if len(sc.CardFaces) > 0 && wanted[sc.CardFaces[0].Name] {
+            found[sc.CardFaces[0].Name] = sc
+        }
+    }
+    return found, nil
+}
+
+
+func mergeScryfall(cd cardData, sc scryfallCard, overwrite bool) (cardData, []string) {
+    conflicts := []string{}
+    // Use the front face for double-faced and split cards
+    face := sc
+    if len(sc.CardFaces) > 0 {
+        face = sc.CardFaces[0]
+    }
+    derived := cardData{
+        Type: scryfallType(face.TypeLine),
+        CastingCost: scryfallMana(face.ManaCost),
+        EntersTapped: entersTappedPattern.MatchString(face.OracleText),
+        Basic: strings.HasPrefix(face.TypeLine, "Basic "),
+    }
+    if match := tapsForPattern.FindStringSubmatch(face.OracleText); match != nil {
+        if match[1] == "one mana of any color" {
+            derived.TapsFor = Mana("G")
+        } else {
+            derived.TapsFor = scryfallMana(match[1])
+        }
+    }
+    conflict := func(field string, have string, want string) bool {
+        if have == want {
+            return false
+        }
+        conflicts = append(conflicts, cd.Name + ": " + field + " is " + have + ", Scryfall says " + want)
+        return overwrite
+    }
+    // A brand new entry takes everything from Scryfall. Otherwise, zero values
+    // may be deliberate, like Elvish Spirit Guide costing nothing because it's
+    // exiled rather than cast.
+    isNew := cd.Type == ""
+    cd.ScryfallID = sc.ID
+    if isNew || conflict("type", cd.Type, derived.Type) {
+        cd.Type = derived.Type
+    }
+    if derived.Type != "land" {
+        if isNew || conflict("casting_cost", cd.CastingCost.Pretty(), derived.CastingCost.Pretty()) {
+            cd.CastingCost = derived.CastingCost
+        }
+    }
+    if derived.Type == "land" {
+        if isNew || conflict("taps_for", cd.TapsFor.Pretty(), derived.TapsFor.Pretty()) {
+            cd.TapsFor = derived.TapsFor
+        }
+    }
+    // Lands like Castle Garenbrig only enter tapped some of the time, so it's
+    // fine for the hand-written value to say tapped when Scryfall's text is
+    // conditional.
+    if derived.EntersTapped && !cd.EntersTapped {
+        if isNew || conflict("enters_tapped", "false", "true") {
+            cd.EntersTapped = true
+        }
+    }
+    if derived.Basic {
+        cd.Basic = true
+    }
+    return cd, conflicts
+}
+
+
+// Map a Scryfall type line onto the types the model knows about
+func scryfallType(typeLine string) string {
+    for _, t := range []string{"Land", "Creature", "Instant", "Sorcery", "Artifact", "Enchantment", "Planeswalker"} {
+        if strings.Contains(typeLine, t) {
+            return strings.ToLower(t)
+        }
+    }
+    return strings.ToLower(typeLine)
+}
+
+
+// Convert a Scryfall cost like "{2}{G}{G}" to mana. We only track green, so
+// any other color just counts toward the total. Hybrid and Phyrexian green
+// count as green.
+func scryfallMana(cost string) mana {
+    m := mana{}
+    for _, match := range manaSymbolPattern.FindAllStringSubmatch(cost, -1) {
+        symbol := match[1]
+        if n, err := strconv.Atoi(symbol); err == nil {
+            m.Total += n
+        } else if symbol == "X" {
+            continue
+        } else if strings.Contains(symbol, "G") {
+            m = m.Plus(Mana("G"))
+        } else {
+            m.Total += 1
+        }
+    }
+    return m
+}
@@ -0,0 +1,394 @@
+package lib
+
+
+import (
+    "container/heap"
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "time"
+)
+
+
+// Two ways to search for a line. Breadth-first plays out every state one turn
+// at a time, which is the original model. Best-first is A*: cost so far is the
+// turn, plus a lower bound on how many more turns it will take to cast Titan.
+// Since the bound never overestimates, the first Titan found is on the
+// earliest possible turn, same as breadth-first, but most of the states that
+// can't get there that fast are never expanded.
+
+
+const (
+    SearchBreadthFirst = "breadth-first"
+    SearchBestFirst = "best-first"
+)
+
+
+var ErrUnknownSearch = errors.New("no such search")
+
+
+// For catching bad names before starting any work
+func CheckSearch(search string) error {
+    switch search {
+        case "", SearchBreadthFirst, SearchBestFirst:
+            return nil
+    }
+    return fmt.Errorf("%w: %s", ErrUnknownSearch, search)
+}
+
+
+// Play the game out to the end with the given search. An empty name means
+// breadth-first.
+func (self *gameManager) Run(search string) (gameManager, error) {
+    start := time.Now()
+    self.stats.start = start
+    game := *self
+    switch search {
+        case "", SearchBreadthFirst:
+            search = SearchBreadthFirst
+            for !game.IsDone() {
+                next, err := game.NextTurn()
+                if err != nil {
+                    return *self, err
+                }
+                game = next
+            }
+        case SearchBestFirst:
+            var err error
+            game, err = self.bestFirst()
+            if err != nil {
+                return *self, err
+            }
+        default:
+            return *self, fmt.Errorf("%w: %s", ErrUnknownSearch, search)
+    }
+    game.stats.Search = search
+    game.stats.Millis = float64(time.Since(start).Microseconds()) / 1000
+    if observer.Game != nil {
+        result, _ := game.Result(FormatNone)
+        observer.Game(result)
+    }
+    return game, nil
+}
+
+
+// Hooks for watching every search the engine runs, like for metrics. Games
+// run concurrently, so these may be called from many goroutines at once.
+type Observer struct {
+    // As the search starts each turn
+    Turn func(TurnProgress)
+    // Once a game is over
+    Game func(GameResult)
+}
+
+
+var observer Observer
+
+
+func SetObserver(o Observer) {
+    observer = o
+}
+
+
+type SearchStats struct {
+    Search string       `json:"search"`
+    // States whose next states were worked out
+    Expanded int        `json:"expanded"`
+    // States dropped because another state was at least as good
+    Pruned int          `json:"pruned"`
+    // Lines that ran out of turns without casting Titan
+    DeadEnds int        `json:"deadEnds"`
+    // The search ran out of time or states before it was done, so a miss
+    // might not be a real miss
+    OutOfBudget bool    `json:"outOfBudget"`
+    Millis float64      `json:"millis"`
+    start time.Time
+    onTurn func(TurnProgress)
+    log *slog.Logger
+    // Once this is done, remaining lines give up, same as running out of budget
+    ctx context.Context
+}
+
+
+// Where the search is at when it starts on a new turn, for showing progress
+// on slow hands
+type TurnProgress struct {
+    Turn int            `json:"turn"`
+    // States waiting to be expanded
+    Frontier int        `json:"frontier"`
+    Expanded int        `json:"expanded"`
+    Pruned int          `json:"pruned"`
+    Millis float64      `json:"millis"`
+}
+
+
+// Call f each time the search starts on a new turn
+func (self *gameManager) OnTurn(f func(TurnProgress)) {
+    self.stats.onTurn = f
+}
+
+
+// Stop the search partway through once ctx is done, like when a job is canceled
+func (self *gameManager) SetContext(ctx context.Context) {
+    self.stats.ctx = ctx
+}
+
+
+func (self *SearchStats) reportTurn(turn int, frontier int) {
+    if self.onTurn == nil && observer.Turn == nil {
+        return
+    }
+    progress := TurnProgress{
+        Turn: turn,
+        Frontier: frontier,
+        Expanded: self.Expanded,
+        Pruned: self.Pruned,
+        Millis: float64(time.Since(self.start).Microseconds()) / 1000,
+    }
+    if self.onTurn != nil {
+        self.onTurn(progress)
+    }
+    if observer.Turn != nil {
+        observer.Turn(progress)
+    }
+}
+
+
+// Tally the lines that gave up while expanding a state. One that was already
+// a dead end is just waiting out the clock, so it doesn't count again.
+func (self *SearchStats) countGiveUps(live bool, state *gameState, next []gameState) {
+    if !live {
+        return
+    }
+    // The state itself only gives up when time or the budget runs out
+    if state.deadEnd {
+        if !self.OutOfBudget {
+            self.log.Debug("out of search budget", "turn", state.turn, "expanded", self.Expanded)
+        }
+        self.OutOfBudget = true
+        return
+    }
+    for _, s := range next {
+        if s.deadEnd {
+            self.DeadEnds += 1
+        }
+    }
+}
+
+
+// Lower bound on how many turns after this one until we can cast Titan. A dead
+// end never gets there. Without Titan or a card that can find it in hand, we
+// have to wait for a draw. With one, it might be this turn, but only if the
+// most mana we could make this turn is enough to pay for Titan.
+func (state *gameState) turnsToTitan() int {
+    if state.deadEnd {
+        return state.budget.MaxTurns + 1 - state.turn
+    }
+    for _, c := range state.hand.Items() {
+        if !c.CanBeTitan() {
+            continue
+        }
+        if state.maxManaThisTurn() >= state.castingCost(state.card("Primeval Titan")).Total {
+            return 0
+        }
+        return 1
+    }
+    return 1
+}
+
+
+// Upper bound on how much mana we could have at once this turn. Mana only
+// comes from the pool, lands coming into play, Elvish Spirit Guide, and
+// Castle Garenbrig, so this adds up the most each of those could give. What
+// things cost is ignored, and a card that draws or searches is assumed to
+// find whatever in the library helps most.
+func (state *gameState) maxManaThisTurn() int {
+    // Cards we might get our hands on this turn
+    reachable := state.hand
+    for _, c := range state.hand.Items() {
+        if findsCards(c) {
+            reachable = reachable.Plus(state.library.arr...)
+            break
+        }
+    }
+    count := func(name string) int {
+        return reachable.Count(state.card(name))
+    }
+    total := state.manaPool.Total
+    if state.turn > 0 {
+        total += count("Elvish Spirit Guide")
+    }
+    // Each land that comes into play makes the most with every Amulet out.
+    // A bounce land can pick up a land that's already out and play it again.
+    amulets := state.battlefield.Count(state.card("Amulet of Vigor")) + count("Amulet of Vigor")
+    perLand := 0
+    for _, c := range append(reachable.Items(), state.battlefield.Items()...) {
+        if !c.IsLand() {
+            continue
+        }
+        m := state.tapsFor(c).Total * max(1, amulets)
+        if c.Name() == "Crumbling Vestige" {
+            m += 1
+        }
+        perLand = max(perLand, m)
+    }
+    lands := state.landPlays + count("Dryad of the Ilysian Grove") + count("Explore") + count("Arboreal Grazer")
+    total += lands * perLand
+    // Castle Garenbrig is activated right before casting Titan or Pact, so it
+    // can only be used once more than there are Pacts
+    castle := state.card("Castle Garenbrig")
+    if state.canActivate(castle) && state.battlefield.Count(castle) + count("Castle Garenbrig") > 0 {
+        total += max(0, 6 - castle.ActivationCost().Total) * (1 + count("Summoner's Pact"))
+    }
+    return total
+}
+
+
+func findsCards(c card) bool {
+    switch c.Name() {
+        case "Abundant Harvest", "Adventurous Impulse", "Ancient Stirrings", "Explore", "Summoner's Pact":
+            return true
+    }
+    return false
+}
+
+
+type queuedState struct {
+    state gameState
+    hash uint64
+    // Earliest turn this state could cast Titan
+    bound int
+}
+
+
+// Order by bound. Break ties toward later turns and more mana, which are
+// closer to done.
+type stateQueue []queuedState
+
+
+func (self stateQueue) Len() int {
+    return len(self)
+}
+
+
+func (self stateQueue) Less(i, j int) bool {
+    if self[i].bound != self[j].bound {
+        return self[i].bound < self[j].bound
+    }
+    if self[i].state.turn != self[j].state.turn {
+        return self[i].state.turn > self[j].state.turn
+    }
+    return self[i].state.manaPool.Total > self[j].state.manaPool.Total
+}
+
+
+func (self stateQueue) Swap(i, j int) {
+    self[i], self[j] = self[j], self[i]
+}
+
+
+func (self *stateQueue) Push(x interface{}) {
+    *self = append(*self, x.(queuedState))
+}
+
+
+func (self *stateQueue) Pop() interface{} {
+    old := *self
+    n := len(old)
+    item := old[n-1]
+    *self = old[:n-1]
+    return item
+}
+
+
+func (self *gameManager) bestFirst() (gameManager, error) {
+    queue := &stateQueue{}
+    // States from different turns share a queue, but duplicates and dominated
+    // states are still only dropped within a turn. Each turn gets a manager to
+    // keep track. States stay in their manager after they're expanded, so they
+    // can still knock out worse states that show up later.
+    turns := make(map[int]*gameManager)
+    expandedHashes := make(map[int]map[uint64]bool)
+    push := func(state gameState) {
+        m, ok := turns[state.turn]
+        if !ok {
+            fresh := GameManager()
+            fresh.stats = self.stats
+            m = &fresh
+            turns[state.turn] = m
+            expandedHashes[state.turn] = make(map[uint64]bool)
+        }
+        hash := state.Hash()
+        if _, ok := m.states[hash]; ok {
+            return
+        }
+        m.Add(state)
+        if _, ok := m.states[hash]; !ok {
+            return
+        }
+        heap.Push(queue, queuedState{state: state, hash: hash, bound: state.turn + state.turnsToTitan()})
+    }
+    for _, state := range self.states {
+        push(state)
+    }
+    // If nothing gets there, show the longest line we tried, like
+    // breadth-first does
+    var bestState *gameState
+    // Turns aren't searched in order, so report each one the first time we
+    // get to it
+    lastTurn := 0
+    for queue.Len() > 0 {
+        item := heap.Pop(queue).(queuedState)
+        state := item.state
+        // Skip states that were knocked out after they were queued
+        if _, ok := turns[state.turn].states[item.hash]; !ok || expandedHashes[state.turn][item.hash] {
+            continue
+        }
+        if state.turn > lastTurn {
+            lastTurn = state.turn
+            self.stats.reportTurn(state.turn, queue.Len() + 1)
+        }
+        expandedHashes[state.turn][item.hash] = true
+        self.stats.Expanded += 1
+        live := !state.deadEnd
+        if self.stats.exhausted(state.budget) {
+            state.giveUp("out of search budget")
+        }
+        next, err := state.NextStates()
+        if err != nil {
+            return *self, err
+        }
+        self.stats.countGiveUps(live, &state, next)
+        for _, stateNew := range next {
+            // Successes only happen mid-turn, so nothing left in the queue
+            // can beat this one
+            if stateNew.success {
+                ret := GameManager(stateNew)
+                ret.stats = self.stats
+                return ret, nil
+            }
+            if stateNew.turn > stateNew.budget.MaxTurns {
+                if bestState == nil || stateNew.LogSize() > bestState.LogSize() {
+                    s := stateNew
+                    bestState = &s
+                }
+                continue
+            }
+            push(stateNew)
+        }
+    }
+    // Every line died outright, like failing to pay for Pact. Show the
+    // starting position.
+    if bestState == nil {
+        s, err := self.Pop()
+        if err != nil {
+            return *self, err
+        }
+        bestState = &s
+    }
+    bestState.giveUp("")
+    ret := GameManager(*bestState)
+    ret.stats = self.stats
+    return ret, nil
+}
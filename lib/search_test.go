@@ -0,0 +1,93 @@
+package lib
+
+
+import (
+    "io/ioutil"
+    "log/slog"
+    "os"
+    "strconv"
+    "testing"
+)
+
+
+// Seeds for the slowest hands from the bench command, all on the draw. Most
+// are Amulet with bounce lands, where breadth-first blows up.
+var hardHandSeeds = []int64{15, 22, 28, 32}
+
+
+func TestMain(m *testing.M) {
+    // Tests run from lib, so the data files are one up
+    SetDataFiles(os.DirFS(".."), "..")
+    // The engine logs every turn, which would drown out the results
+    slog.SetDefault(slog.New(slog.NewTextHandler(ioutil.Discard, nil)))
+    os.Exit(m.Run())
+}
+
+
+func BenchmarkBreadthFirst(b *testing.B) {
+    benchmarkSearch(b, SearchBreadthFirst)
+}
+
+
+func BenchmarkBestFirst(b *testing.B) {
+    benchmarkSearch(b, SearchBestFirst)
+}
+
+
+func benchmarkSearch(b *testing.B, search string) {
+    deck, err := LoadDecklist(DefaultDeck)
+    if err != nil {
+        b.Fatal(err)
+    }
+    budget := DefaultBudget()
+    budget.MaxTurns = 4
+    for _, seed := range hardHandSeeds {
+        shuffled := ShuffledSeed(deck.Main, seed)
+        b.Run("seed=" + strconv.FormatInt(seed, 10), func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                game, err := NewGame(deck.Name, shuffled[7:], shuffled[:7], false, false, budget, OpponentProfile{}, seed)
+                if err != nil {
+                    b.Fatal(err)
+                }
+                _, err = game.Run(search)
+                if err != nil {
+                    b.Fatal(err)
+                }
+            }
+        })
+    }
+}
+
+
+// Both searches should find Titan on the same turn, or both miss
+func TestSearchesAgree(t *testing.T) {
+    deck, err := LoadDecklist(DefaultDeck)
+    if err != nil {
+        t.Fatal(err)
+    }
+    budget := DefaultBudget()
+    budget.MaxTurns = 4
+    for _, seed := range hardHandSeeds {
+        shuffled := ShuffledSeed(deck.Main, seed)
+        turns := []int{}
+        for _, search := range []string{SearchBreadthFirst, SearchBestFirst} {
+            game, err := NewGame(deck.Name, shuffled[7:], shuffled[:7], false, false, budget, OpponentProfile{}, seed)
+            if err != nil {
+                t.Fatal(err)
+            }
+            game, err = game.Run(search)
+            if err != nil {
+                t.Fatal(err)
+            }
+            turn := -1
+            if game.success {
+                turn = game.turn
+            }
+            turns = append(turns, turn)
+        }
+        if turns[0] != turns[1] {
+            t.Errorf("seed %d: breadth-first got turn %d, best-first got turn %d", seed, turns[0], turns[1])
+        }
+    }
+}
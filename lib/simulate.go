@@ -0,0 +1,224 @@
+package lib
+
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+)
+
+
+// Monte Carlo runs over many games, for questions one game can't answer. How
+// often does this hand cast Titan on time? How fast is this deck? Is this
+// hand better than a mulligan to six? These take too long for one request,
+// so the server runs them as jobs.
+
+
+const (
+    // Play the same hand against many shuffles of the rest of the deck
+    SimHand = "hand"
+    // Deal and play many fresh hands from the deck
+    SimGoldfish = "goldfish"
+    // Play the hand, and compare against fresh six-card hands. This is a rough
+    // stand-in for the London mulligan, since the model doesn't know which
+    // card to put on the bottom.
+    SimMulligan = "mulligan"
+)
+
+
+var ErrBadSimulation = errors.New("bad simulation")
+
+
+type SimSpec struct {
+    Kind string             `json:"kind"`
+    Deck string             `json:"deck,omitempty"`
+    // Opening hand, for hand and mulligan runs. These cards come out of the
+    // deck before shuffling.
+    Hand []string           `json:"hand,omitempty"`
+    // Leave empty to alternate between the play and the draw
+    OnThePlay *bool         `json:"onThePlay,omitempty"`
+    Trials int              `json:"trials"`
+    // Trial i shuffles with seed+i. Leave empty for a random seed.
+    Seed *int64             `json:"seed,omitempty"`
+    Search string           `json:"search,omitempty"`
+}
+
+
+type SimTally struct {
+    Games int               `json:"games"`
+    Successes int           `json:"successes"`
+    // Successes on each turn, starting with turn 1
+    ByTurn []int            `json:"byTurn"`
+    Rate float64            `json:"rate"`
+}
+
+
+func (self *SimTally) add(result GameResult) {
+    self.Games += 1
+    if result.Success {
+        self.Successes += 1
+        for len(self.ByTurn) < result.Turn {
+            self.ByTurn = append(self.ByTurn, 0)
+        }
+        self.ByTurn[result.Turn-1] += 1
+    }
+    self.Rate = float64(self.Successes) / float64(self.Games)
+}
+
+
+type SimResult struct {
+    Games SimTally          `json:"games"`
+    // Six-card hands, for mulligan runs
+    Mulligan *SimTally      `json:"mulligan,omitempty"`
+}
+
+
+// Deep copy, since the tallies keep changing as the run goes
+func (self SimResult) clone() SimResult {
+    ret := SimResult{Games: self.Games}
+    ret.Games.ByTurn = append([]int{}, self.Games.ByTurn...)
+    if self.Mulligan != nil {
+        mulligan := *self.Mulligan
+        mulligan.ByTurn = append([]int{}, self.Mulligan.ByTurn...)
+        ret.Mulligan = &mulligan
+    }
+    return ret
+}
+
+
+type Simulation struct {
+    Spec SimSpec
+    budget Budget
+    deck []string
+    // What's left of the deck once the hand is taken out
+    rest []string
+    log *slog.Logger
+}
+
+
+// Log every game with l, like to tag them with the request that asked
+func (self *Simulation) SetLogger(l *slog.Logger) {
+    self.log = l
+}
+
+
+// Check the spec and load the deck up front, so bad requests fail right away
+// rather than once the job gets to the front of the queue
+func NewSimulation(spec SimSpec, budget Budget) (*Simulation, error) {
+    if spec.Trials < 1 {
+        return nil, fmt.Errorf("%w: need at least one trial", ErrBadSimulation)
+    }
+    err := CheckSearch(spec.Search)
+    if err != nil {
+        return nil, err
+    }
+    if spec.Seed == nil {
+        seed := NewSeed()
+        spec.Seed = &seed
+    }
+    deck, err := LoadDecklist(spec.Deck)
+    if err != nil {
+        return nil, err
+    }
+    spec.Deck = deck.Name
+    sim := Simulation{Spec: spec, budget: budget, deck: deck.Main, log: slog.Default()}
+    switch spec.Kind {
+        case SimGoldfish:
+            if len(spec.Hand) > 0 {
+                return nil, fmt.Errorf("%w: goldfish runs deal their own hands", ErrBadSimulation)
+            }
+            return &sim, nil
+        case SimHand, SimMulligan:
+            if len(spec.Hand) == 0 {
+                return nil, fmt.Errorf("%w: %s runs need a hand", ErrBadSimulation, spec.Kind)
+            }
+        default:
+            return nil, fmt.Errorf("%w: no such kind: %s", ErrBadSimulation, spec.Kind)
+    }
+    hand, err := ResolveCardNames(spec.Hand)
+    if err != nil {
+        return nil, err
+    }
+    rest := countNames(deck.Main)
+    for _, name := range hand {
+        if rest[name] == 0 {
+            return nil, fmt.Errorf("%w: not enough copies in the deck: %s", ErrBadSimulation, name)
+        }
+        rest[name]--
+    }
+    sim.Spec.Hand = hand
+    sim.rest = namesFromCounts(rest)
+    return &sim, nil
+}
+
+
+// How many games the whole run plays
+func (self *Simulation) Total() int {
+    if self.Spec.Kind == SimMulligan {
+        return 2 * self.Spec.Trials
+    }
+    return self.Spec.Trials
+}
+
+
+// Play every trial, calling progress after each game with a copy of the tally
+// so far. If ctx is canceled, the game in progress gives up and isn't counted.
+func (self *Simulation) Run(ctx context.Context, progress func(SimResult, int)) (SimResult, error) {
+    result := SimResult{}
+    if self.Spec.Kind == SimMulligan {
+        result.Mulligan = &SimTally{}
+    }
+    done := 0
+    for i := 0; i < self.Spec.Trials; i++ {
+        seed := *self.Spec.Seed + int64(i)
+        otp := i % 2 == 0
+        if self.Spec.OnThePlay != nil {
+            otp = *self.Spec.OnThePlay
+        }
+        hand, library := self.Spec.Hand, ShuffledSeed(self.rest, seed)
+        if self.Spec.Kind == SimGoldfish {
+            shuffled := ShuffledSeed(self.deck, seed)
+            hand, library = shuffled[:7], shuffled[7:]
+        }
+        games := []*SimTally{&result.Games}
+        deals := [][2][]string{{hand, library}}
+        if self.Spec.Kind == SimMulligan {
+            shuffled := ShuffledSeed(self.deck, seed)
+            games = append(games, result.Mulligan)
+            deals = append(deals, [2][]string{shuffled[:6], shuffled[6:]})
+        }
+        for j, deal := range deals {
+            if ctx.Err() != nil {
+                return result, ctx.Err()
+            }
+            gameResult, err := self.play(ctx, deal[0], deal[1], otp, seed)
+            if err != nil {
+                return result, err
+            }
+            games[j].add(gameResult)
+            done += 1
+            progress(result.clone(), done)
+        }
+    }
+    return result, nil
+}
+
+
+func (self *Simulation) play(ctx context.Context, hand []string, library []string, otp bool, seed int64) (GameResult, error) {
+    game, err := NewGame(self.Spec.Deck, library, hand, otp, false, self.budget, OpponentProfile{}, seed)
+    if err != nil {
+        return GameResult{}, err
+    }
+    game.SetLogger(self.log)
+    game.SetContext(ctx)
+    game, err = game.Run(self.Spec.Search)
+    if err != nil {
+        return GameResult{}, err
+    }
+    // A game cut short says nothing about the hand
+    if ctx.Err() != nil {
+        return GameResult{}, ctx.Err()
+    }
+    return game.Result(FormatNone)
+}
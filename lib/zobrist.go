@@ -0,0 +1,66 @@
+package lib
+
+
+// States are deduplicated by a 64-bit Zobrist-style hash. Each feature of the
+// state (three copies of card X in hand, card Y at position 4 in the library,
+// two land plays left) gets a pseudo-random key, and the hash is the XOR of
+// the keys present. Rather than keeping tables of keys that would have to grow
+// as cards are interned, each key is computed by scrambling the feature with
+// a fixed mixing function. Collisions are possible in principle, but at 64
+// bits they won't come up in a search this size.
+
+
+const (
+    zobristHand uint64 = iota + 1
+    zobristBattlefield
+    zobristLibrary
+    zobristScalar
+)
+
+
+// Scalar features, each hashed with its value
+const (
+    zobristManaGreen uint64 = iota + 1
+    zobristManaTotal
+    zobristSuccess
+    zobristDeadEnd
+    zobristLandPlays
+    zobristSpellsCast
+    zobristOpponentSpent
+    zobristOpponentDestroyed
+)
+
+
+// The splitmix64 finalizer. Every input bit affects every output bit.
+func mix64(x uint64) uint64 {
+    x ^= x >> 30
+    x *= 0xbf58476d1ce4e5b9
+    x ^= x >> 27
+    x *= 0x94d049bb133111eb
+    x ^= x >> 31
+    return x
+}
+
+
+func zobristKey(kind uint64, a uint64, b uint64) uint64 {
+    return mix64(mix64(kind<<56 ^ a) ^ b)
+}
+
+
+func zobristCardMap(kind uint64, cm cardMap) uint64 {
+    h := uint64(0)
+    for id, n := range cm.counts {
+        if n > 0 {
+            h ^= zobristKey(kind, uint64(id), uint64(n))
+        }
+    }
+    return h
+}
+
+
+func zobristBool(feature uint64, b bool) uint64 {
+    if !b {
+        return 0
+    }
+    return zobristKey(zobristScalar, feature, 1)
+}
@@ -1,141 +1,551 @@
 package main
 
 import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
     "encoding/json"
+    "errors"
     "fmt"
+    "io/ioutil"
     "log"
-    "math/rand"
+    "log/slog"
+    "net"
     "net/http"
+    "net/http/pprof"
+    "os"
+    "os/signal"
+    "strconv"
+    "strings"
+    "syscall"
     "time"
 
+    "github.com/charles-uno/mtgserver/api"
     "github.com/charles-uno/mtgserver/lib"
+    "github.com/charles-uno/mtgserver/metrics"
+    "github.com/charles-uno/mtgserver/rpc"
+    "github.com/charles-uno/mtgserver/service"
     "github.com/rs/cors"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
 )
 
 
-type openingHand struct {
-    Hand        []string    `json:"hand"`
-    Library     []string    `json:"library"`
-    OnThePlay   bool        `json:"onThePlay"`
-    Verbose     bool        `json:"verbose"`
+// The operations behind every endpoint, shared with the gRPC server
+var svc *service.Service
+
+
+// Errors from the service say whose fault they are
+func errorStatus(err error) int {
+    switch service.KindOf(err) {
+        case service.BadRequest:
+            return http.StatusBadRequest
+        case service.NotFound:
+            return http.StatusNotFound
+    }
+    return http.StatusInternalServerError
 }
 
 
-func handleOpeningHand(w http.ResponseWriter, r *http.Request) {
-    deck, err := lib.LoadDeck()
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    err := json.NewEncoder(w).Encode(v)
     if err != nil {
-        reply := map[string]string{"error": err.Error()}
-        b, _ := json.Marshal(reply)
-        http.Error(w, string(b), http.StatusInternalServerError)
-        log.Println("failed to load deck at /api/hand")
+        slog.Error("failed to write reply", "err", err)
+    }
+}
+
+
+// Every error reply has the same shape: {"error": {"status", "message"}}
+func writeError(w http.ResponseWriter, status int, err error) {
+    body := api.Error{Status: status, Message: err.Error()}
+    var unknown *lib.UnknownCardError
+    if errors.As(err, &unknown) {
+        body.Suggestions = unknown.Suggestions
+    }
+    writeJSON(w, status, api.ErrorReply{Error: body})
+}
+
+
+func handleDecks(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodPost {
+        handleDeckUpload(w, r)
         return
     }
-    oh := openingHand{
-        Hand: deck[:7],
-        Library: deck[7:],
-        OnThePlay: flip(),
-        Verbose: false,
+    names, err := lib.ListDecks()
+    if err != nil {
+        writeError(w, http.StatusInternalServerError, err)
+        service.Logger(r.Context()).Error("failed to list decks", "err", err)
+        return
     }
-    log.Println("endpoint hit: /api/hand")
-    json.NewEncoder(w).Encode(oh)
+    service.Logger(r.Context()).Info("endpoint hit")
+    writeJSON(w, http.StatusOK, api.Decks{Decks: names, Default: lib.DefaultDeck})
 }
 
 
-func handleSequencing(w http.ResponseWriter, r *http.Request) {
-    oh := openingHand{}
-    err := json.NewDecoder(r.Body).Decode(&oh)
+func handleDeckUpload(w http.ResponseWriter, r *http.Request) {
+    logger := service.Logger(r.Context())
+    // A decklist is a few hundred bytes. Don't let anyone fill up the disk.
+    text, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1 << 16))
+    if err != nil {
+        writeError(w, http.StatusBadRequest, err)
+        logger.Warn("bad payload", "err", err)
+        return
+    }
+    id, problems, err := lib.SaveDecklist(string(text), r.URL.Query().Get("format"))
     if err != nil {
-        reply := map[string]string{"error": err.Error()}
-        b, _ := json.Marshal(reply)
-        http.Error(w, string(b), http.StatusBadRequest)
-        log.Println("bad payload at /api/play")
+        writeError(w, http.StatusInternalServerError, err)
+        logger.Error("failed to save deck", "err", err)
         return
     }
-    maxTurns := 4
+    status := http.StatusCreated
+    if len(problems) > 0 {
+        status = http.StatusUnprocessableEntity
+        logger.Info("rejected deck upload", "problems", len(problems))
+    } else {
+        logger.Info("saved deck", "deck", id)
+    }
+    writeJSON(w, status, api.DeckReport{ID: id, Problems: problems})
+}
 
-    log.Println(oh)
 
-    game, err := lib.NewGame(
-        lib.Shuffled(oh.Library),
-        oh.Hand,
-        oh.OnThePlay,
-        oh.Verbose,
-        maxTurns,
-    )
+func handleOpeningHand(w http.ResponseWriter, r *http.Request) {
+    logger := service.Logger(r.Context())
+    oh, err := svc.DealHand(r.URL.Query().Get("deck"))
+    if err != nil {
+        writeError(w, errorStatus(err), err)
+        logger.Warn("failed to load deck", "err", err)
+        return
+    }
+    logger.Info("endpoint hit")
+    writeJSON(w, http.StatusOK, oh)
+}
+
+
+func handleSideboard(w http.ResponseWriter, r *http.Request) {
+    logger := service.Logger(r.Context())
+    deckName := r.URL.Query().Get("deck")
+    // GET shows the decklist so the client knows what it can board in
+    if r.Method == http.MethodGet {
+        deck, err := svc.Decklist(deckName)
+        if err != nil {
+            writeError(w, errorStatus(err), err)
+            logger.Warn("failed to load deck", "err", err)
+            return
+        }
+        logger.Info("endpoint hit")
+        writeJSON(w, http.StatusOK, deck)
+        return
+    }
+    sr := api.SideboardRequest{}
+    err := json.NewDecoder(r.Body).Decode(&sr)
+    if err != nil {
+        writeError(w, http.StatusBadRequest, err)
+        logger.Warn("bad payload", "err", err)
+        return
+    }
+    oh, err := svc.Sideboard(deckName, sr)
+    if err != nil {
+        writeError(w, errorStatus(err), err)
+        logger.Warn("failed to sideboard", "err", err)
+        return
+    }
+    logger.Info("endpoint hit")
+    writeJSON(w, http.StatusOK, oh)
+}
+
+
+// The body for /play is a hand to play
+func parsePlayRequest(r *http.Request) (*service.Game, error) {
+    hand := api.OpeningHand{}
+    err := json.NewDecoder(r.Body).Decode(&hand)
     if err != nil {
-        reply := map[string]string{"error": err.Error()}
-        b, _ := json.Marshal(reply)
-        http.Error(w, string(b), http.StatusInternalServerError)
-        log.Println("failed to start game at /api/play")
+        return nil, &service.Error{Kind: service.BadRequest, Err: err}
+    }
+    service.Logger(r.Context()).Debug("playing hand", "hand", hand)
+    return svc.NewGame(r.Context(), hand, r.URL.Query().Get("format"))
+}
+
+
+// The query string for /e2e says how to deal a hand from a deck
+func parseEndToEndRequest(r *http.Request) (*service.Game, error) {
+    query := r.URL.Query()
+    req := service.DealRequest{
+        Deck: query.Get("deck"),
+        Opponent: query.Get("opponent"),
+        Search: query.Get("search"),
+        Format: query.Get("format"),
+    }
+    if raw := query.Get("seed"); raw != "" {
+        seed, err := strconv.ParseInt(raw, 10, 64)
+        if err != nil {
+            return nil, &service.Error{Kind: service.BadRequest, Err: fmt.Errorf("bad seed: %w", err)}
+        }
+        req.Seed = &seed
+    }
+    var err error
+    req.Turns, err = queryInt(r, "turns")
+    if err == nil {
+        req.TimeoutMillis, err = queryInt(r, "timeoutMillis")
+    }
+    if err == nil {
+        req.Game, err = queryInt(r, "game")
+    }
+    if err != nil {
+        return nil, &service.Error{Kind: service.BadRequest, Err: err}
+    }
+    return svc.DealGame(r.Context(), req)
+}
+
+
+// Reply with the result once the game is over
+func gameHandler(parse func(*http.Request) (*service.Game, error)) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        logger := service.Logger(r.Context())
+        game, err := parse(r)
+        if err != nil {
+            writeError(w, errorStatus(err), err)
+            logger.Warn("bad request", "err", err)
+            return
+        }
+        result, err := game.Play(nil)
+        if err != nil {
+            writeError(w, errorStatus(err), err)
+            logger.Warn("failed to play game", "err", err)
+            return
+        }
+        writeJSON(w, http.StatusOK, result)
+        logger.Info("done with calculation", "success", result.Success, "turn", result.Turn, "expanded", result.Stats.Expanded)
+    }
+}
+
+
+// Stream Server-Sent Events: a "turn" event as the search starts each turn,
+// then a "result" event with the same reply as the non-streaming endpoint.
+// Problems found before the stream starts get a normal error reply. After
+// that, they're sent as an "error" event.
+func streamHandler(parse func(*http.Request) (*service.Game, error)) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        logger := service.Logger(r.Context())
+        game, err := parse(r)
+        if err != nil {
+            writeError(w, errorStatus(err), err)
+            logger.Warn("bad request", "err", err)
+            return
+        }
+        w.Header().Set("Content-Type", "text/event-stream")
+        w.Header().Set("Cache-Control", "no-cache")
+        w.WriteHeader(http.StatusOK)
+        result, err := game.Play(func(progress lib.TurnProgress) {
+            writeEvent(w, "turn", progress)
+        })
+        if err != nil {
+            writeEvent(w, "error", api.ErrorReply{Error: api.Error{Status: errorStatus(err), Message: err.Error()}})
+            logger.Warn("failed to play game", "err", err)
+            return
+        }
+        writeEvent(w, "result", result)
+        logger.Info("done with calculation", "success", result.Success, "turn", result.Turn, "expanded", result.Stats.Expanded)
+    }
+}
+
+
+func writeEvent(w http.ResponseWriter, name string, v interface{}) {
+    b, err := json.Marshal(v)
+    if err != nil {
+        slog.Error("failed to write event", "err", err)
         return
     }
-    // Iterate through the turns
-    for !game.IsDone() {
-        game = game.NextTurn()
+    fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, b)
+    if flusher, ok := w.(http.Flusher); ok {
+        flusher.Flush()
     }
-    fmt.Fprintf(w, game.ToJSON())
-    log.Println("done with calculation at /api/play")
-    fmt.Println(game.Pretty())
 }
 
 
-func handleEndToEnd(w http.ResponseWriter, r *http.Request) {
-    deck, err := lib.LoadDeck()
+// Simulations too long for one request run in the background
+var jobs *lib.JobQueue
+
+
+func handleJobSubmit(w http.ResponseWriter, r *http.Request) {
+    logger := service.Logger(r.Context())
+    req := api.JobRequest{}
+    err := json.NewDecoder(r.Body).Decode(&req)
     if err != nil {
-        reply := map[string]string{"error": err.Error()}
-        b, _ := json.Marshal(reply)
-        http.Error(w, string(b), http.StatusInternalServerError)
-        log.Println("failed to load deck at /api/e2e")
+        writeError(w, http.StatusBadRequest, err)
+        logger.Warn("bad payload", "err", err)
         return
     }
-    oh := openingHand{
-        Hand: deck[:7],
-        Library: deck[7:],
-        OnThePlay: flip(),
-        Verbose: false,
-    }
-    maxTurns := 4
-    game, err := lib.NewGame(
-        lib.Shuffled(oh.Library),
-        oh.Hand,
-        oh.OnThePlay,
-        oh.Verbose,
-        maxTurns,
-    )
+    // The job outlives the request, but its games still log the request ID
+    sim, err := svc.NewSimulation(r.Context(), req)
     if err != nil {
-        reply := map[string]string{"error": err.Error()}
-        b, _ := json.Marshal(reply)
-        http.Error(w, string(b), http.StatusInternalServerError)
-        log.Println("failed to start game at /api/e2e")
+        writeError(w, errorStatus(err), err)
+        logger.Warn("bad simulation", "err", err)
         return
     }
-    // Iterate through the turns
-    for !game.IsDone() {
-        game = game.NextTurn()
+    status, err := jobs.Submit(sim)
+    if err != nil {
+        writeError(w, http.StatusServiceUnavailable, err)
+        logger.Warn("not queuing job", "err", err)
+        return
+    }
+    w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/") + "/" + status.ID)
+    writeJSON(w, http.StatusAccepted, status)
+    logger.Info("queued job", "job_id", status.ID)
+}
+
+
+// GET polls a job and DELETE cancels it. Either way the reply is its status.
+func handleJob(w http.ResponseWriter, r *http.Request) {
+    id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+    var status lib.JobStatus
+    var err error
+    if r.Method == http.MethodDelete {
+        status, err = jobs.Cancel(id)
+    } else {
+        status, err = jobs.Get(id)
+    }
+    if err != nil {
+        writeError(w, http.StatusNotFound, err)
+        service.Logger(r.Context()).Warn("no such job", "job_id", id)
+        return
+    }
+    service.Logger(r.Context()).Info("endpoint hit")
+    writeJSON(w, http.StatusOK, status)
+}
+
+
+// Optional integer query parameter, zero if missing
+func queryInt(r *http.Request, name string) (int, error) {
+    raw := r.URL.Query().Get(name)
+    if raw == "" {
+        return 0, nil
+    }
+    n, err := strconv.Atoi(raw)
+    if err != nil {
+        return 0, fmt.Errorf("bad %s: %w", name, err)
+    }
+    return n, nil
+}
+
+
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    w.Write(api.Spec)
+}
+
+
+// Turn away methods the API description doesn't list
+func allow(handler http.HandlerFunc, methods ...string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        for _, method := range methods {
+            if r.Method == method {
+                handler(w, r)
+                return
+            }
+        }
+        w.Header().Set("Allow", strings.Join(methods, ", "))
+        writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s not allowed on %s", r.Method, r.URL.Path))
     }
-    fmt.Fprintf(w, game.ToMiniJSON())
-    log.Println("done with calculation at /api/e2e")
-    fmt.Println(game.ToMiniJSON())
-    fmt.Println(game.Pretty())
 }
 
 
 func main() {
-    log.Println("launching service")
+    loaded, args, err := loadConfig(os.Args[1:])
+    if err != nil {
+        log.Fatal(err)
+    }
+    cfg = loaded
+    slog.SetDefault(cfg.logger())
+    lib.SetDataFiles(defaultData, cfg.DataDir)
+    if len(args) > 0 {
+        runCommand(args)
+        return
+    }
+    go checkReady()
+    go reloadOnHangup()
+    if cfg.Watch > 0 {
+        go lib.WatchDataFiles(cfg.Watch)
+    }
+    svc = &service.Service{
+        Limits: cfg.limits(),
+        ShowGames: cfg.ShowGames,
+    }
+    jobs = lib.NewJobQueue(cfg.JobWorkers, cfg.MaxJobs)
+    metrics.ObserveSearch()
+    var grpcServer *grpc.Server
+    if cfg.GRPCListen != "" {
+        grpcServer = serveGRPC(cfg.GRPCListen)
+    }
+    slog.Info("launching service", "listen", cfg.Listen)
     mux := http.NewServeMux()
-    mux.HandleFunc("/api/hand", handleOpeningHand)
-    mux.HandleFunc("/api/play", handleSequencing)
-    mux.HandleFunc("/api/e2e", handleEndToEnd)
-    // Default CORS handler allows GET and POST from anywhere. To go back to
-    // default settings, lose the handler and use nil instead
-    handler := cors.Default().Handler(mux)
-    log.Fatal(http.ListenAndServe(":5001", handler))
+    routes := map[string]http.HandlerFunc{
+        "/decks": allow(handleDecks, http.MethodGet, http.MethodPost),
+        "/hand": allow(handleOpeningHand, http.MethodGet),
+        "/play": allow(gameHandler(parsePlayRequest), http.MethodPost),
+        "/play/stream": allow(streamHandler(parsePlayRequest), http.MethodPost),
+        "/sideboard": allow(handleSideboard, http.MethodGet, http.MethodPost),
+        "/e2e": allow(gameHandler(parseEndToEndRequest), http.MethodGet),
+        "/e2e/stream": allow(streamHandler(parseEndToEndRequest), http.MethodGet),
+        "/jobs": allow(handleJobSubmit, http.MethodPost),
+        "/jobs/": allow(handleJob, http.MethodGet, http.MethodDelete),
+    }
+    for path, handler := range routes {
+        handler = recoverPanics(handler)
+        mux.HandleFunc("/v1"+path, metrics.Instrument("/v1"+path, handler))
+        // The web client still uses the unversioned paths
+        mux.HandleFunc("/api"+path, metrics.Instrument("/api"+path, handler))
+    }
+    mux.HandleFunc("/v1/openapi.json", allow(handleOpenAPI, http.MethodGet))
+    mux.HandleFunc("/healthz", allow(handleHealth, http.MethodGet))
+    mux.HandleFunc("/readyz", allow(handleReady, http.MethodGet))
+    mux.Handle("/metrics", metrics.Handler())
+    // Profiles cost a little and show a lot, so they're opt-in
+    if cfg.PProf {
+        mux.HandleFunc("/debug/pprof/", pprof.Index)
+        mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+        mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+        mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+        mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+    }
+    // Browsers can only call the API from the configured origins
+    handler := cors.New(cors.Options{
+        AllowedOrigins: cfg.CORSOrigins,
+        AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete},
+        ExposedHeaders: []string{"X-Request-ID"},
+    }).Handler(withRequestID(mux))
+    httpServer := &http.Server{Addr: cfg.Listen, Handler: handler}
+    go func() {
+        err := httpServer.ListenAndServe()
+        if !errors.Is(err, http.ErrServerClosed) {
+            log.Fatal(err)
+        }
+    }()
+    stop := make(chan os.Signal, 1)
+    signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+    <-stop
+    shutdown(httpServer, grpcServer, cfg.ShutdownTimeout)
 }
 
 
-func flip() bool {
-    // Random generator should be seeded from shuffling, but let's be sure. We
-    // only call this once per game anyway.
-    rand.Seed(time.Now().UTC().UnixNano())
-    return rand.Intn(2) == 0
+func serveGRPC(addr string) *grpc.Server {
+    listener, err := net.Listen("tcp", addr)
+    if err != nil {
+        log.Fatal(err)
+    }
+    server := grpc.NewServer(
+        grpc.ChainUnaryInterceptor(logUnary, recoverUnary),
+        grpc.ChainStreamInterceptor(logStream, recoverStream),
+    )
+    rpc.RegisterSimulatorServer(server, rpc.NewServer(svc))
+    slog.Info("launching gRPC service", "listen", addr)
+    go func() {
+        // Only returns nil once the server is stopped
+        err := server.Serve(listener)
+        if err != nil {
+            log.Fatal(err)
+        }
+    }()
+    return server
+}
+
+
+// Tag each request with an ID, and log everything about it with that ID. If
+// the caller sent X-Request-ID, use theirs. Either way it goes back in the
+// reply, so the caller can quote it.
+func withRequestID(handler http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        id := requestID(r.Header.Get("X-Request-ID"))
+        w.Header().Set("X-Request-ID", id)
+        logger := slog.Default().With("request_id", id, "method", r.Method, "path", r.URL.Path)
+        handler.ServeHTTP(w, r.WithContext(service.WithLogger(r.Context(), logger)))
+    })
+}
+
+
+// The caller's ID if it looks sane, otherwise a fresh one
+func requestID(given string) string {
+    if given != "" && len(given) <= 64 {
+        return given
+    }
+    b := make([]byte, 8)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
+
+
+// Same for gRPC calls, with the ID in x-request-id metadata
+func grpcLogger(ctx context.Context, method string) context.Context {
+    given := ""
+    if md, ok := metadata.FromIncomingContext(ctx); ok {
+        if ids := md.Get("x-request-id"); len(ids) > 0 {
+            given = ids[0]
+        }
+    }
+    id := requestID(given)
+    grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+    logger := slog.Default().With("request_id", id, "method", method)
+    return service.WithLogger(ctx, logger)
+}
+
+
+// Log and count gRPC calls like the HTTP handlers do
+func logUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+    start := time.Now()
+    ctx = grpcLogger(ctx, info.FullMethod)
+    reply, err := handler(ctx, req)
+    logCall(ctx, info.FullMethod, start, err)
+    return reply, err
+}
+
+
+func logStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+    start := time.Now()
+    ctx := grpcLogger(stream.Context(), info.FullMethod)
+    err := handler(srv, &loggedStream{ServerStream: stream, ctx: ctx})
+    logCall(ctx, info.FullMethod, start, err)
+    return err
+}
+
+
+// Hands the tagged context to the handler
+type loggedStream struct {
+    grpc.ServerStream
+    ctx context.Context
+}
+
+
+func (self *loggedStream) Context() context.Context {
+    return self.ctx
+}
+
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+    metrics.ObserveRequest("grpc", method, status.Code(err).String(), start)
+    if err != nil {
+        service.Logger(ctx).Warn("call failed", "err", err)
+        return
+    }
+    service.Logger(ctx).Info("call done")
+}
+
+
+func reloadOnHangup() {
+    hangups := make(chan os.Signal, 1)
+    signal.Notify(hangups, syscall.SIGHUP)
+    for range hangups {
+        slog.Info("reloading card data on SIGHUP")
+        // A server that never got ready gets another chance. One that's
+        // already serving keeps its old data if the new data is bad.
+        if notReady.Load().(string) != "" {
+            checkReady()
+            continue
+        }
+        err := lib.ReloadCardData()
+        if err != nil {
+            slog.Error("not reloading card data", "err", err)
+        }
+    }
 }
@@ -0,0 +1,129 @@
+package metrics
+
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/charles-uno/mtgserver/lib"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+
+// Prometheus metrics for the server and the search behind it, served at
+// /metrics. Requests are counted per endpoint. Every game the engine plays
+// is counted too, whether it came from a request, a job, or gRPC.
+
+
+var (
+    requests = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "mtgserver_requests_total",
+        Help: "Requests handled, by transport, endpoint, and status code.",
+    }, []string{"transport", "endpoint", "code"})
+    requestSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+        Name: "mtgserver_request_duration_seconds",
+        Help: "Time to handle a request, by transport and endpoint.",
+        Buckets: prometheus.ExponentialBuckets(0.001, 4, 9),
+    }, []string{"transport", "endpoint"})
+    games = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "mtgserver_games_total",
+        Help: "Games played out, by search and whether Titan was cast.",
+    }, []string{"search", "success"})
+    expanded = promauto.NewHistogramVec(prometheus.HistogramOpts{
+        Name: "mtgserver_search_expanded_states",
+        Help: "States expanded per game, by search.",
+        Buckets: prometheus.ExponentialBuckets(10, 4, 9),
+    }, []string{"search"})
+    frontier = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name: "mtgserver_search_frontier_states",
+        Help: "States waiting to be expanded as the search starts each turn.",
+        Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+    })
+    outOfBudget = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "mtgserver_search_out_of_budget_total",
+        Help: "Games where the search ran out of time or states, by search.",
+    }, []string{"search"})
+    deadEnds = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "mtgserver_search_dead_ends_total",
+        Help: "Lines that ran out of turns without casting Titan, by search.",
+    }, []string{"search"})
+    successTurn = promauto.NewHistogram(prometheus.HistogramOpts{
+        Name: "mtgserver_success_turn",
+        Help: "Turn Titan was cast, for games that got there.",
+        Buckets: prometheus.LinearBuckets(1, 1, 8),
+    })
+)
+
+
+// Watch every game the engine plays
+func ObserveSearch() {
+    lib.SetObserver(lib.Observer{
+        Turn: func(progress lib.TurnProgress) {
+            frontier.Observe(float64(progress.Frontier))
+        },
+        Game: func(result lib.GameResult) {
+            search := result.Stats.Search
+            games.WithLabelValues(search, strconv.FormatBool(result.Success)).Inc()
+            expanded.WithLabelValues(search).Observe(float64(result.Stats.Expanded))
+            deadEnds.WithLabelValues(search).Add(float64(result.Stats.DeadEnds))
+            if result.Stats.OutOfBudget {
+                outOfBudget.WithLabelValues(search).Inc()
+            }
+            if result.Success {
+                successTurn.Observe(float64(result.Turn))
+            }
+        },
+    })
+}
+
+
+// Count a finished request. The endpoint should be a route, not the full
+// path, so job IDs don't each get their own series.
+func ObserveRequest(transport string, endpoint string, code string, start time.Time) {
+    requests.WithLabelValues(transport, endpoint, code).Inc()
+    requestSeconds.WithLabelValues(transport, endpoint).Observe(time.Since(start).Seconds())
+}
+
+
+// Wrap an HTTP handler to count its requests under the given route
+func Instrument(endpoint string, handler http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        start := time.Now()
+        sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+        handler(sw, r)
+        ObserveRequest("http", endpoint, strconv.Itoa(sw.status), start)
+    }
+}
+
+
+// Remembers the status code. Streaming endpoints need to flush, so that
+// gets passed through.
+type statusWriter struct {
+    http.ResponseWriter
+    status int
+    wroteHeader bool
+}
+
+
+func (self *statusWriter) WriteHeader(status int) {
+    if !self.wroteHeader {
+        self.status = status
+        self.wroteHeader = true
+    }
+    self.ResponseWriter.WriteHeader(status)
+}
+
+
+func (self *statusWriter) Flush() {
+    if flusher, ok := self.ResponseWriter.(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
+
+func Handler() http.Handler {
+    return promhttp.Handler()
+}
@@ -0,0 +1,1430 @@
+// gRPC version of the /v1 API, for services that want typed messages over
+// the network. Messages mirror the JSON bodies in api/openapi.json, field for
+// field. Regenerate the Go code after changing this file with
+// `go generate ./rpc`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.2
+// 	protoc        (unknown)
+// source: mtgserver.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DealHandRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Leave empty for the default deck
+	Deck string `protobuf:"bytes,1,opt,name=deck,proto3" json:"deck,omitempty"`
+}
+
+func (x *DealHandRequest) Reset() {
+	*x = DealHandRequest{}
+	mi := &file_mtgserver_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealHandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealHandRequest) ProtoMessage() {}
+
+func (x *DealHandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealHandRequest.ProtoReflect.Descriptor instead.
+func (*DealHandRequest) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DealHandRequest) GetDeck() string {
+	if x != nil {
+		return x.Deck
+	}
+	return ""
+}
+
+type OpeningHand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Deck      string   `protobuf:"bytes,1,opt,name=deck,proto3" json:"deck,omitempty"`
+	Hand      []string `protobuf:"bytes,2,rep,name=hand,proto3" json:"hand,omitempty"`
+	Library   []string `protobuf:"bytes,3,rep,name=library,proto3" json:"library,omitempty"`
+	OnThePlay bool     `protobuf:"varint,4,opt,name=on_the_play,json=onThePlay,proto3" json:"on_the_play,omitempty"`
+	Verbose   bool     `protobuf:"varint,5,opt,name=verbose,proto3" json:"verbose,omitempty"`
+	// Games 2 and 3 are post-board, so the opponent may have hate pieces
+	Game int32 `protobuf:"varint,6,opt,name=game,proto3" json:"game,omitempty"`
+	// Either the name of a profile from opponents.yaml or a full profile.
+	// Leave both empty to goldfish.
+	Opponent        string           `protobuf:"bytes,7,opt,name=opponent,proto3" json:"opponent,omitempty"`
+	OpponentProfile *OpponentProfile `protobuf:"bytes,8,opt,name=opponent_profile,json=opponentProfile,proto3" json:"opponent_profile,omitempty"`
+	// breadth-first (the default) or best-first
+	Search string `protobuf:"bytes,9,opt,name=search,proto3" json:"search,omitempty"`
+	// Seed for shuffling the library and rolling the opponent's cards. Leave
+	// empty for a random one.
+	Seed *int64 `protobuf:"varint,10,opt,name=seed,proto3,oneof" json:"seed,omitempty"`
+	// Ask for less search than the server default. Zero means the default.
+	Turns         int32 `protobuf:"varint,11,opt,name=turns,proto3" json:"turns,omitempty"`
+	TimeoutMillis int32 `protobuf:"varint,12,opt,name=timeout_millis,json=timeoutMillis,proto3" json:"timeout_millis,omitempty"`
+}
+
+func (x *OpeningHand) Reset() {
+	*x = OpeningHand{}
+	mi := &file_mtgserver_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpeningHand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpeningHand) ProtoMessage() {}
+
+func (x *OpeningHand) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpeningHand.ProtoReflect.Descriptor instead.
+func (*OpeningHand) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OpeningHand) GetDeck() string {
+	if x != nil {
+		return x.Deck
+	}
+	return ""
+}
+
+func (x *OpeningHand) GetHand() []string {
+	if x != nil {
+		return x.Hand
+	}
+	return nil
+}
+
+func (x *OpeningHand) GetLibrary() []string {
+	if x != nil {
+		return x.Library
+	}
+	return nil
+}
+
+func (x *OpeningHand) GetOnThePlay() bool {
+	if x != nil {
+		return x.OnThePlay
+	}
+	return false
+}
+
+func (x *OpeningHand) GetVerbose() bool {
+	if x != nil {
+		return x.Verbose
+	}
+	return false
+}
+
+func (x *OpeningHand) GetGame() int32 {
+	if x != nil {
+		return x.Game
+	}
+	return 0
+}
+
+func (x *OpeningHand) GetOpponent() string {
+	if x != nil {
+		return x.Opponent
+	}
+	return ""
+}
+
+func (x *OpeningHand) GetOpponentProfile() *OpponentProfile {
+	if x != nil {
+		return x.OpponentProfile
+	}
+	return nil
+}
+
+func (x *OpeningHand) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *OpeningHand) GetSeed() int64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+func (x *OpeningHand) GetTurns() int32 {
+	if x != nil {
+		return x.Turns
+	}
+	return 0
+}
+
+func (x *OpeningHand) GetTimeoutMillis() int32 {
+	if x != nil {
+		return x.TimeoutMillis
+	}
+	return 0
+}
+
+type OpponentProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name        string        `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Disruptions []*Disruption `protobuf:"bytes,2,rep,name=disruptions,proto3" json:"disruptions,omitempty"`
+}
+
+func (x *OpponentProfile) Reset() {
+	*x = OpponentProfile{}
+	mi := &file_mtgserver_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpponentProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpponentProfile) ProtoMessage() {}
+
+func (x *OpponentProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpponentProfile.ProtoReflect.Descriptor instead.
+func (*OpponentProfile) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OpponentProfile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OpponentProfile) GetDisruptions() []*Disruption {
+	if x != nil {
+		return x.Disruptions
+	}
+	return nil
+}
+
+type Disruption struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// One of "discard", "counter", "removal", or "hate"
+	Kind        string   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Card        string   `protobuf:"bytes,2,opt,name=card,proto3" json:"card,omitempty"`
+	Turn        int32    `protobuf:"varint,3,opt,name=turn,proto3" json:"turn,omitempty"`
+	Targets     []string `protobuf:"bytes,4,rep,name=targets,proto3" json:"targets,omitempty"`
+	Probability float64  `protobuf:"fixed64,5,opt,name=probability,proto3" json:"probability,omitempty"`
+	X           int32    `protobuf:"varint,6,opt,name=x,proto3" json:"x,omitempty"`
+	Sideboard   bool     `protobuf:"varint,7,opt,name=sideboard,proto3" json:"sideboard,omitempty"`
+}
+
+func (x *Disruption) Reset() {
+	*x = Disruption{}
+	mi := &file_mtgserver_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Disruption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Disruption) ProtoMessage() {}
+
+func (x *Disruption) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Disruption.ProtoReflect.Descriptor instead.
+func (*Disruption) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Disruption) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Disruption) GetCard() string {
+	if x != nil {
+		return x.Card
+	}
+	return ""
+}
+
+func (x *Disruption) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *Disruption) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *Disruption) GetProbability() float64 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+func (x *Disruption) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Disruption) GetSideboard() bool {
+	if x != nil {
+		return x.Sideboard
+	}
+	return false
+}
+
+type PlayHandRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hand *OpeningHand `protobuf:"bytes,1,opt,name=hand,proto3" json:"hand,omitempty"`
+	// tags (the default), events, text, or none
+	Format string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+}
+
+func (x *PlayHandRequest) Reset() {
+	*x = PlayHandRequest{}
+	mi := &file_mtgserver_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayHandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayHandRequest) ProtoMessage() {}
+
+func (x *PlayHandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayHandRequest.ProtoReflect.Descriptor instead.
+func (*PlayHandRequest) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PlayHandRequest) GetHand() *OpeningHand {
+	if x != nil {
+		return x.Hand
+	}
+	return nil
+}
+
+func (x *PlayHandRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type GameResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Turn Titan was cast, or -1 if it never was
+	Turn      int32 `protobuf:"varint,2,opt,name=turn,proto3" json:"turn,omitempty"`
+	OnThePlay bool  `protobuf:"varint,3,opt,name=on_the_play,json=onThePlay,proto3" json:"on_the_play,omitempty"`
+	// Seed the game was played with, to replay it
+	Seed   int64        `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	Stats  *SearchStats `protobuf:"bytes,5,opt,name=stats,proto3" json:"stats,omitempty"`
+	Plays  []*PlayTag   `protobuf:"bytes,6,rep,name=plays,proto3" json:"plays,omitempty"`
+	Events []*Event     `protobuf:"bytes,7,rep,name=events,proto3" json:"events,omitempty"`
+	Text   string       `protobuf:"bytes,8,opt,name=text,proto3" json:"text,omitempty"`
+}
+
+func (x *GameResult) Reset() {
+	*x = GameResult{}
+	mi := &file_mtgserver_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameResult) ProtoMessage() {}
+
+func (x *GameResult) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameResult.ProtoReflect.Descriptor instead.
+func (*GameResult) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GameResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GameResult) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *GameResult) GetOnThePlay() bool {
+	if x != nil {
+		return x.OnThePlay
+	}
+	return false
+}
+
+func (x *GameResult) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *GameResult) GetStats() *SearchStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *GameResult) GetPlays() []*PlayTag {
+	if x != nil {
+		return x.Plays
+	}
+	return nil
+}
+
+func (x *GameResult) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GameResult) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type SearchStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Search   string  `protobuf:"bytes,1,opt,name=search,proto3" json:"search,omitempty"`
+	Expanded int64   `protobuf:"varint,2,opt,name=expanded,proto3" json:"expanded,omitempty"`
+	Pruned   int64   `protobuf:"varint,3,opt,name=pruned,proto3" json:"pruned,omitempty"`
+	Millis   float64 `protobuf:"fixed64,4,opt,name=millis,proto3" json:"millis,omitempty"`
+	// Lines that ran out of turns without casting Titan
+	DeadEnds int64 `protobuf:"varint,5,opt,name=dead_ends,json=deadEnds,proto3" json:"dead_ends,omitempty"`
+	// The search ran out of time or states, so a miss might not be a real miss
+	OutOfBudget bool `protobuf:"varint,6,opt,name=out_of_budget,json=outOfBudget,proto3" json:"out_of_budget,omitempty"`
+}
+
+func (x *SearchStats) Reset() {
+	*x = SearchStats{}
+	mi := &file_mtgserver_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchStats) ProtoMessage() {}
+
+func (x *SearchStats) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchStats.ProtoReflect.Descriptor instead.
+func (*SearchStats) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchStats) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *SearchStats) GetExpanded() int64 {
+	if x != nil {
+		return x.Expanded
+	}
+	return 0
+}
+
+func (x *SearchStats) GetPruned() int64 {
+	if x != nil {
+		return x.Pruned
+	}
+	return 0
+}
+
+func (x *SearchStats) GetMillis() float64 {
+	if x != nil {
+		return x.Millis
+	}
+	return 0
+}
+
+func (x *SearchStats) GetDeadEnds() int64 {
+	if x != nil {
+		return x.DeadEnds
+	}
+	return 0
+}
+
+func (x *SearchStats) GetOutOfBudget() bool {
+	if x != nil {
+		return x.OutOfBudget
+	}
+	return false
+}
+
+type PlayTag struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type   string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Text   string `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Target string `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+}
+
+func (x *PlayTag) Reset() {
+	*x = PlayTag{}
+	mi := &file_mtgserver_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayTag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayTag) ProtoMessage() {}
+
+func (x *PlayTag) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayTag.ProtoReflect.Descriptor instead.
+func (*PlayTag) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PlayTag) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PlayTag) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *PlayTag) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type        string           `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Turn        int32            `protobuf:"varint,2,opt,name=turn,proto3" json:"turn,omitempty"`
+	Cards       []string         `protobuf:"bytes,3,rep,name=cards,proto3" json:"cards,omitempty"`
+	Text        string           `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	Hand        map[string]int32 `protobuf:"bytes,5,rep,name=hand,proto3" json:"hand,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Battlefield map[string]int32 `protobuf:"bytes,6,rep,name=battlefield,proto3" json:"battlefield,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	ManaPool    string           `protobuf:"bytes,7,opt,name=mana_pool,json=manaPool,proto3" json:"mana_pool,omitempty"`
+	LibrarySize int32            `protobuf:"varint,8,opt,name=library_size,json=librarySize,proto3" json:"library_size,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_mtgserver_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *Event) GetCards() []string {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+func (x *Event) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Event) GetHand() map[string]int32 {
+	if x != nil {
+		return x.Hand
+	}
+	return nil
+}
+
+func (x *Event) GetBattlefield() map[string]int32 {
+	if x != nil {
+		return x.Battlefield
+	}
+	return nil
+}
+
+func (x *Event) GetManaPool() string {
+	if x != nil {
+		return x.ManaPool
+	}
+	return ""
+}
+
+func (x *Event) GetLibrarySize() int32 {
+	if x != nil {
+		return x.LibrarySize
+	}
+	return 0
+}
+
+// Same as a simulation job, but the kind comes from the method
+type EvaluateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Leave empty for the default deck
+	Deck string `protobuf:"bytes,1,opt,name=deck,proto3" json:"deck,omitempty"`
+	// These cards come out of the deck before shuffling
+	Hand []string `protobuf:"bytes,2,rep,name=hand,proto3" json:"hand,omitempty"`
+	// Leave empty to alternate between the play and the draw
+	OnThePlay *bool `protobuf:"varint,3,opt,name=on_the_play,json=onThePlay,proto3,oneof" json:"on_the_play,omitempty"`
+	Trials    int32 `protobuf:"varint,4,opt,name=trials,proto3" json:"trials,omitempty"`
+	// Trial i shuffles with seed+i. Leave empty for a random seed.
+	Seed          *int64 `protobuf:"varint,5,opt,name=seed,proto3,oneof" json:"seed,omitempty"`
+	Search        string `protobuf:"bytes,6,opt,name=search,proto3" json:"search,omitempty"`
+	Turns         int32  `protobuf:"varint,7,opt,name=turns,proto3" json:"turns,omitempty"`
+	TimeoutMillis int32  `protobuf:"varint,8,opt,name=timeout_millis,json=timeoutMillis,proto3" json:"timeout_millis,omitempty"`
+}
+
+func (x *EvaluateRequest) Reset() {
+	*x = EvaluateRequest{}
+	mi := &file_mtgserver_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateRequest) ProtoMessage() {}
+
+func (x *EvaluateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateRequest.ProtoReflect.Descriptor instead.
+func (*EvaluateRequest) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *EvaluateRequest) GetDeck() string {
+	if x != nil {
+		return x.Deck
+	}
+	return ""
+}
+
+func (x *EvaluateRequest) GetHand() []string {
+	if x != nil {
+		return x.Hand
+	}
+	return nil
+}
+
+func (x *EvaluateRequest) GetOnThePlay() bool {
+	if x != nil && x.OnThePlay != nil {
+		return *x.OnThePlay
+	}
+	return false
+}
+
+func (x *EvaluateRequest) GetTrials() int32 {
+	if x != nil {
+		return x.Trials
+	}
+	return 0
+}
+
+func (x *EvaluateRequest) GetSeed() int64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+func (x *EvaluateRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *EvaluateRequest) GetTurns() int32 {
+	if x != nil {
+		return x.Turns
+	}
+	return 0
+}
+
+func (x *EvaluateRequest) GetTimeoutMillis() int32 {
+	if x != nil {
+		return x.TimeoutMillis
+	}
+	return 0
+}
+
+type SimTally struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Games     int32 `protobuf:"varint,1,opt,name=games,proto3" json:"games,omitempty"`
+	Successes int32 `protobuf:"varint,2,opt,name=successes,proto3" json:"successes,omitempty"`
+	// Successes on each turn, starting with turn 1
+	ByTurn []int32 `protobuf:"varint,3,rep,packed,name=by_turn,json=byTurn,proto3" json:"by_turn,omitempty"`
+	Rate   float64 `protobuf:"fixed64,4,opt,name=rate,proto3" json:"rate,omitempty"`
+}
+
+func (x *SimTally) Reset() {
+	*x = SimTally{}
+	mi := &file_mtgserver_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimTally) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimTally) ProtoMessage() {}
+
+func (x *SimTally) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimTally.ProtoReflect.Descriptor instead.
+func (*SimTally) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SimTally) GetGames() int32 {
+	if x != nil {
+		return x.Games
+	}
+	return 0
+}
+
+func (x *SimTally) GetSuccesses() int32 {
+	if x != nil {
+		return x.Successes
+	}
+	return 0
+}
+
+func (x *SimTally) GetByTurn() []int32 {
+	if x != nil {
+		return x.ByTurn
+	}
+	return nil
+}
+
+func (x *SimTally) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type SimResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Games *SimTally `protobuf:"bytes,1,opt,name=games,proto3" json:"games,omitempty"`
+	// Six-card hands, for mulligan runs
+	Mulligan *SimTally `protobuf:"bytes,2,opt,name=mulligan,proto3" json:"mulligan,omitempty"`
+}
+
+func (x *SimResult) Reset() {
+	*x = SimResult{}
+	mi := &file_mtgserver_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimResult) ProtoMessage() {}
+
+func (x *SimResult) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimResult.ProtoReflect.Descriptor instead.
+func (*SimResult) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SimResult) GetGames() *SimTally {
+	if x != nil {
+		return x.Games
+	}
+	return nil
+}
+
+func (x *SimResult) GetMulligan() *SimTally {
+	if x != nil {
+		return x.Mulligan
+	}
+	return nil
+}
+
+type TurnProgress struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Turn int32 `protobuf:"varint,1,opt,name=turn,proto3" json:"turn,omitempty"`
+	// States waiting to be expanded
+	Frontier int32   `protobuf:"varint,2,opt,name=frontier,proto3" json:"frontier,omitempty"`
+	Expanded int64   `protobuf:"varint,3,opt,name=expanded,proto3" json:"expanded,omitempty"`
+	Pruned   int64   `protobuf:"varint,4,opt,name=pruned,proto3" json:"pruned,omitempty"`
+	Millis   float64 `protobuf:"fixed64,5,opt,name=millis,proto3" json:"millis,omitempty"`
+}
+
+func (x *TurnProgress) Reset() {
+	*x = TurnProgress{}
+	mi := &file_mtgserver_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TurnProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TurnProgress) ProtoMessage() {}
+
+func (x *TurnProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TurnProgress.ProtoReflect.Descriptor instead.
+func (*TurnProgress) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TurnProgress) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *TurnProgress) GetFrontier() int32 {
+	if x != nil {
+		return x.Frontier
+	}
+	return 0
+}
+
+func (x *TurnProgress) GetExpanded() int64 {
+	if x != nil {
+		return x.Expanded
+	}
+	return 0
+}
+
+func (x *TurnProgress) GetPruned() int64 {
+	if x != nil {
+		return x.Pruned
+	}
+	return 0
+}
+
+func (x *TurnProgress) GetMillis() float64 {
+	if x != nil {
+		return x.Millis
+	}
+	return 0
+}
+
+type SearchUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Update:
+	//	*SearchUpdate_Turn
+	//	*SearchUpdate_Result
+	Update isSearchUpdate_Update `protobuf_oneof:"update"`
+}
+
+func (x *SearchUpdate) Reset() {
+	*x = SearchUpdate{}
+	mi := &file_mtgserver_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchUpdate) ProtoMessage() {}
+
+func (x *SearchUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_mtgserver_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchUpdate.ProtoReflect.Descriptor instead.
+func (*SearchUpdate) Descriptor() ([]byte, []int) {
+	return file_mtgserver_proto_rawDescGZIP(), []int{13}
+}
+
+func (m *SearchUpdate) GetUpdate() isSearchUpdate_Update {
+	if m != nil {
+		return m.Update
+	}
+	return nil
+}
+
+func (x *SearchUpdate) GetTurn() *TurnProgress {
+	if x, ok := x.GetUpdate().(*SearchUpdate_Turn); ok {
+		return x.Turn
+	}
+	return nil
+}
+
+func (x *SearchUpdate) GetResult() *GameResult {
+	if x, ok := x.GetUpdate().(*SearchUpdate_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+type isSearchUpdate_Update interface {
+	isSearchUpdate_Update()
+}
+
+type SearchUpdate_Turn struct {
+	Turn *TurnProgress `protobuf:"bytes,1,opt,name=turn,proto3,oneof"`
+}
+
+type SearchUpdate_Result struct {
+	Result *GameResult `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*SearchUpdate_Turn) isSearchUpdate_Update() {}
+
+func (*SearchUpdate_Result) isSearchUpdate_Update() {}
+
+var File_mtgserver_proto protoreflect.FileDescriptor
+
+var file_mtgserver_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x0c, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x22,
+	0x25, 0x0a, 0x0f, 0x44, 0x65, 0x61, 0x6c, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x65, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x64, 0x65, 0x63, 0x6b, 0x22, 0xfa, 0x02, 0x0a, 0x0b, 0x4f, 0x70, 0x65, 0x6e, 0x69,
+	0x6e, 0x67, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x65, 0x63, 0x6b, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x65, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61,
+	0x6e, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x12, 0x18,
+	0x0a, 0x07, 0x6c, 0x69, 0x62, 0x72, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x07, 0x6c, 0x69, 0x62, 0x72, 0x61, 0x72, 0x79, 0x12, 0x1e, 0x0a, 0x0b, 0x6f, 0x6e, 0x5f, 0x74,
+	0x68, 0x65, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6f,
+	0x6e, 0x54, 0x68, 0x65, 0x50, 0x6c, 0x61, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x62,
+	0x6f, 0x73, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x76, 0x65, 0x72, 0x62, 0x6f,
+	0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x67, 0x61, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x67, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x70, 0x70, 0x6f, 0x6e, 0x65,
+	0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x70, 0x70, 0x6f, 0x6e, 0x65,
+	0x6e, 0x74, 0x12, 0x48, 0x0a, 0x10, 0x6f, 0x70, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x5f, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6d,
+	0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x70, 0x6f,
+	0x6e, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x0f, 0x6f, 0x70, 0x70,
+	0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65,
+	0x61, 0x72, 0x63, 0x68, 0x12, 0x17, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x03, 0x48, 0x00, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x75, 0x72, 0x6e, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x75,
+	0x72, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x74, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x73,
+	0x65, 0x65, 0x64, 0x22, 0x61, 0x0a, 0x0f, 0x4f, 0x70, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x3a, 0x0a, 0x0b, 0x64, 0x69,
+	0x73, 0x72, 0x75, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x69, 0x73, 0x72, 0x75, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x64, 0x69, 0x73, 0x72, 0x75,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xb0, 0x01, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x72, 0x75,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x61, 0x72,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x61, 0x72, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x75, 0x72, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x74, 0x75, 0x72,
+	0x6e, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x70,
+	0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0b, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x0c, 0x0a,
+	0x01, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x78, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x69, 0x64, 0x65, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09,
+	0x73, 0x69, 0x64, 0x65, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x22, 0x58, 0x0a, 0x0f, 0x50, 0x6c, 0x61,
+	0x79, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x04,
+	0x68, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x74, 0x67,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e,
+	0x67, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x66,
+	0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x22, 0x8d, 0x02, 0x0a, 0x0a, 0x47, 0x61, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x75, 0x72, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x74, 0x75, 0x72, 0x6e,
+	0x12, 0x1e, 0x0a, 0x0b, 0x6f, 0x6e, 0x5f, 0x74, 0x68, 0x65, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6f, 0x6e, 0x54, 0x68, 0x65, 0x50, 0x6c, 0x61, 0x79,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x73, 0x65, 0x65, 0x64, 0x12, 0x2f, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05,
+	0x73, 0x74, 0x61, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x05, 0x70, 0x6c, 0x61, 0x79, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6c, 0x61, 0x79, 0x54, 0x61, 0x67, 0x52, 0x05, 0x70, 0x6c, 0x61,
+	0x79, 0x73, 0x12, 0x2b, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x65, 0x78, 0x74, 0x22, 0xb2, 0x01, 0x0a, 0x0b, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x65,
+	0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x65,
+	0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x75, 0x6e, 0x65,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x70, 0x72, 0x75, 0x6e, 0x65, 0x64, 0x12,
+	0x16, 0x0a, 0x06, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x61, 0x64, 0x5f,
+	0x65, 0x6e, 0x64, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x65, 0x61, 0x64,
+	0x45, 0x6e, 0x64, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x6f, 0x75, 0x74, 0x5f, 0x6f, 0x66, 0x5f, 0x62,
+	0x75, 0x64, 0x67, 0x65, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6f, 0x75, 0x74,
+	0x4f, 0x66, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x22, 0x49, 0x0a, 0x07, 0x50, 0x6c, 0x61, 0x79,
+	0x54, 0x61, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x22, 0x8d, 0x03, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x75, 0x72, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x04, 0x74, 0x75, 0x72, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12,
+	0x31, 0x0a, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x68, 0x61,
+	0x6e, 0x64, 0x12, 0x46, 0x0a, 0x0b, 0x62, 0x61, 0x74, 0x74, 0x6c, 0x65, 0x66, 0x69, 0x65, 0x6c,
+	0x64, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x42, 0x61, 0x74,
+	0x74, 0x6c, 0x65, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x62,
+	0x61, 0x74, 0x74, 0x6c, 0x65, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61,
+	0x6e, 0x61, 0x5f, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d,
+	0x61, 0x6e, 0x61, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x69, 0x62, 0x72, 0x61,
+	0x72, 0x79, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6c,
+	0x69, 0x62, 0x72, 0x61, 0x72, 0x79, 0x53, 0x69, 0x7a, 0x65, 0x1a, 0x37, 0x0a, 0x09, 0x48, 0x61,
+	0x6e, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x1a, 0x3e, 0x0a, 0x10, 0x42, 0x61, 0x74, 0x74, 0x6c, 0x65, 0x66, 0x69, 0x65,
+	0x6c, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0xfd, 0x01, 0x0a, 0x0f, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x65, 0x63, 0x6b, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x65, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x68,
+	0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x12,
+	0x23, 0x0a, 0x0b, 0x6f, 0x6e, 0x5f, 0x74, 0x68, 0x65, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x09, 0x6f, 0x6e, 0x54, 0x68, 0x65, 0x50, 0x6c, 0x61,
+	0x79, 0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x72, 0x69, 0x61, 0x6c, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x72, 0x69, 0x61, 0x6c, 0x73, 0x12, 0x17, 0x0a, 0x04,
+	0x73, 0x65, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x48, 0x01, 0x52, 0x04, 0x73, 0x65,
+	0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x75, 0x72, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x75,
+	0x72, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x74, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6f,
+	0x6e, 0x5f, 0x74, 0x68, 0x65, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x73,
+	0x65, 0x65, 0x64, 0x22, 0x6b, 0x0a, 0x08, 0x53, 0x69, 0x6d, 0x54, 0x61, 0x6c, 0x6c, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x67, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x67, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x65, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x62, 0x79, 0x5f, 0x74, 0x75, 0x72, 0x6e, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x05, 0x52, 0x06, 0x62, 0x79, 0x54, 0x75, 0x72, 0x6e, 0x12, 0x12, 0x0a, 0x04,
+	0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x72, 0x61, 0x74, 0x65,
+	0x22, 0x6d, 0x0a, 0x09, 0x53, 0x69, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2c, 0x0a,
+	0x05, 0x67, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d,
+	0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x6d, 0x54,
+	0x61, 0x6c, 0x6c, 0x79, 0x52, 0x05, 0x67, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x32, 0x0a, 0x08, 0x6d,
+	0x75, 0x6c, 0x6c, 0x69, 0x67, 0x61, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x6d,
+	0x54, 0x61, 0x6c, 0x6c, 0x79, 0x52, 0x08, 0x6d, 0x75, 0x6c, 0x6c, 0x69, 0x67, 0x61, 0x6e, 0x22,
+	0x8a, 0x01, 0x0a, 0x0c, 0x54, 0x75, 0x72, 0x6e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x75, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x74, 0x75, 0x72, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x72, 0x6f, 0x6e, 0x74, 0x69, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x66, 0x72, 0x6f, 0x6e, 0x74, 0x69, 0x65, 0x72,
+	0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x70, 0x72, 0x75, 0x6e, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x70, 0x72,
+	0x75, 0x6e, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x22, 0x7e, 0x0a, 0x0c,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x30, 0x0a, 0x04,
+	0x74, 0x75, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6d, 0x74, 0x67,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x75, 0x72, 0x6e, 0x50, 0x72,
+	0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x48, 0x00, 0x52, 0x04, 0x74, 0x75, 0x72, 0x6e, 0x12, 0x32,
+	0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
+	0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61,
+	0x6d, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x42, 0x08, 0x0a, 0x06, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x32, 0xef, 0x02, 0x0a,
+	0x09, 0x53, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x44, 0x0a, 0x08, 0x44, 0x65,
+	0x61, 0x6c, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x1d, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x48, 0x61, 0x6e, 0x64,
+	0x12, 0x43, 0x0a, 0x08, 0x50, 0x6c, 0x61, 0x79, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x1d, 0x2e, 0x6d,
+	0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6c, 0x61, 0x79,
+	0x48, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x6d, 0x74,
+	0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x46, 0x0a, 0x0c, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74,
+	0x65, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x1d, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x42, 0x0a,
+	0x08, 0x4d, 0x75, 0x6c, 0x6c, 0x69, 0x67, 0x61, 0x6e, 0x12, 0x1d, 0x2e, 0x6d, 0x74, 0x67, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x4b, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x12, 0x1d, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x50, 0x6c, 0x61, 0x79, 0x48, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1a, 0x2e, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x30, 0x01, 0x42, 0x26,
+	0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x68, 0x61,
+	0x72, 0x6c, 0x65, 0x73, 0x2d, 0x75, 0x6e, 0x6f, 0x2f, 0x6d, 0x74, 0x67, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x2f, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_mtgserver_proto_rawDescOnce sync.Once
+	file_mtgserver_proto_rawDescData = file_mtgserver_proto_rawDesc
+)
+
+func file_mtgserver_proto_rawDescGZIP() []byte {
+	file_mtgserver_proto_rawDescOnce.Do(func() {
+		file_mtgserver_proto_rawDescData = protoimpl.X.CompressGZIP(file_mtgserver_proto_rawDescData)
+	})
+	return file_mtgserver_proto_rawDescData
+}
+
+var file_mtgserver_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_mtgserver_proto_goTypes = []any{
+	(*DealHandRequest)(nil), // 0: mtgserver.v1.DealHandRequest
+	(*OpeningHand)(nil),     // 1: mtgserver.v1.OpeningHand
+	(*OpponentProfile)(nil), // 2: mtgserver.v1.OpponentProfile
+	(*Disruption)(nil),      // 3: mtgserver.v1.Disruption
+	(*PlayHandRequest)(nil), // 4: mtgserver.v1.PlayHandRequest
+	(*GameResult)(nil),      // 5: mtgserver.v1.GameResult
+	(*SearchStats)(nil),     // 6: mtgserver.v1.SearchStats
+	(*PlayTag)(nil),         // 7: mtgserver.v1.PlayTag
+	(*Event)(nil),           // 8: mtgserver.v1.Event
+	(*EvaluateRequest)(nil), // 9: mtgserver.v1.EvaluateRequest
+	(*SimTally)(nil),        // 10: mtgserver.v1.SimTally
+	(*SimResult)(nil),       // 11: mtgserver.v1.SimResult
+	(*TurnProgress)(nil),    // 12: mtgserver.v1.TurnProgress
+	(*SearchUpdate)(nil),    // 13: mtgserver.v1.SearchUpdate
+	nil,                     // 14: mtgserver.v1.Event.HandEntry
+	nil,                     // 15: mtgserver.v1.Event.BattlefieldEntry
+}
+var file_mtgserver_proto_depIdxs = []int32{
+	2,  // 0: mtgserver.v1.OpeningHand.opponent_profile:type_name -> mtgserver.v1.OpponentProfile
+	3,  // 1: mtgserver.v1.OpponentProfile.disruptions:type_name -> mtgserver.v1.Disruption
+	1,  // 2: mtgserver.v1.PlayHandRequest.hand:type_name -> mtgserver.v1.OpeningHand
+	6,  // 3: mtgserver.v1.GameResult.stats:type_name -> mtgserver.v1.SearchStats
+	7,  // 4: mtgserver.v1.GameResult.plays:type_name -> mtgserver.v1.PlayTag
+	8,  // 5: mtgserver.v1.GameResult.events:type_name -> mtgserver.v1.Event
+	14, // 6: mtgserver.v1.Event.hand:type_name -> mtgserver.v1.Event.HandEntry
+	15, // 7: mtgserver.v1.Event.battlefield:type_name -> mtgserver.v1.Event.BattlefieldEntry
+	10, // 8: mtgserver.v1.SimResult.games:type_name -> mtgserver.v1.SimTally
+	10, // 9: mtgserver.v1.SimResult.mulligan:type_name -> mtgserver.v1.SimTally
+	12, // 10: mtgserver.v1.SearchUpdate.turn:type_name -> mtgserver.v1.TurnProgress
+	5,  // 11: mtgserver.v1.SearchUpdate.result:type_name -> mtgserver.v1.GameResult
+	0,  // 12: mtgserver.v1.Simulator.DealHand:input_type -> mtgserver.v1.DealHandRequest
+	4,  // 13: mtgserver.v1.Simulator.PlayHand:input_type -> mtgserver.v1.PlayHandRequest
+	9,  // 14: mtgserver.v1.Simulator.EvaluateHand:input_type -> mtgserver.v1.EvaluateRequest
+	9,  // 15: mtgserver.v1.Simulator.Mulligan:input_type -> mtgserver.v1.EvaluateRequest
+	4,  // 16: mtgserver.v1.Simulator.StreamSearch:input_type -> mtgserver.v1.PlayHandRequest
+	1,  // 17: mtgserver.v1.Simulator.DealHand:output_type -> mtgserver.v1.OpeningHand
+	5,  // 18: mtgserver.v1.Simulator.PlayHand:output_type -> mtgserver.v1.GameResult
+	11, // 19: mtgserver.v1.Simulator.EvaluateHand:output_type -> mtgserver.v1.SimResult
+	11, // 20: mtgserver.v1.Simulator.Mulligan:output_type -> mtgserver.v1.SimResult
+	13, // 21: mtgserver.v1.Simulator.StreamSearch:output_type -> mtgserver.v1.SearchUpdate
+	17, // [17:22] is the sub-list for method output_type
+	12, // [12:17] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_mtgserver_proto_init() }
+func file_mtgserver_proto_init() {
+	if File_mtgserver_proto != nil {
+		return
+	}
+	file_mtgserver_proto_msgTypes[1].OneofWrappers = []any{}
+	file_mtgserver_proto_msgTypes[9].OneofWrappers = []any{}
+	file_mtgserver_proto_msgTypes[13].OneofWrappers = []any{
+		(*SearchUpdate_Turn)(nil),
+		(*SearchUpdate_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_mtgserver_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mtgserver_proto_goTypes,
+		DependencyIndexes: file_mtgserver_proto_depIdxs,
+		MessageInfos:      file_mtgserver_proto_msgTypes,
+	}.Build()
+	File_mtgserver_proto = out.File
+	file_mtgserver_proto_rawDesc = nil
+	file_mtgserver_proto_goTypes = nil
+	file_mtgserver_proto_depIdxs = nil
+}
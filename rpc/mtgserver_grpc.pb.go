@@ -0,0 +1,294 @@
+// gRPC version of the /v1 API, for services that want typed messages over
+// the network. Messages mirror the JSON bodies in api/openapi.json, field for
+// field. Regenerate the Go code after changing this file with
+// `go generate ./rpc`.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: mtgserver.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Simulator_DealHand_FullMethodName     = "/mtgserver.v1.Simulator/DealHand"
+	Simulator_PlayHand_FullMethodName     = "/mtgserver.v1.Simulator/PlayHand"
+	Simulator_EvaluateHand_FullMethodName = "/mtgserver.v1.Simulator/EvaluateHand"
+	Simulator_Mulligan_FullMethodName     = "/mtgserver.v1.Simulator/Mulligan"
+	Simulator_StreamSearch_FullMethodName = "/mtgserver.v1.Simulator/StreamSearch"
+)
+
+// SimulatorClient is the client API for Simulator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SimulatorClient interface {
+	// Seven cards from a fresh shuffle of a deck on the server
+	DealHand(ctx context.Context, in *DealHandRequest, opts ...grpc.CallOption) (*OpeningHand, error)
+	// Play a hand out and return the result once the game is over
+	PlayHand(ctx context.Context, in *PlayHandRequest, opts ...grpc.CallOption) (*GameResult, error)
+	// Play one hand against many shuffles of the rest of the deck
+	EvaluateHand(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*SimResult, error)
+	// Play a hand, and compare against fresh six-card hands
+	Mulligan(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*SimResult, error)
+	// Like PlayHand, but send progress as the search starts each turn. The
+	// last update is the result.
+	StreamSearch(ctx context.Context, in *PlayHandRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchUpdate], error)
+}
+
+type simulatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimulatorClient(cc grpc.ClientConnInterface) SimulatorClient {
+	return &simulatorClient{cc}
+}
+
+func (c *simulatorClient) DealHand(ctx context.Context, in *DealHandRequest, opts ...grpc.CallOption) (*OpeningHand, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OpeningHand)
+	err := c.cc.Invoke(ctx, Simulator_DealHand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorClient) PlayHand(ctx context.Context, in *PlayHandRequest, opts ...grpc.CallOption) (*GameResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GameResult)
+	err := c.cc.Invoke(ctx, Simulator_PlayHand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorClient) EvaluateHand(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*SimResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimResult)
+	err := c.cc.Invoke(ctx, Simulator_EvaluateHand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorClient) Mulligan(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*SimResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimResult)
+	err := c.cc.Invoke(ctx, Simulator_Mulligan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorClient) StreamSearch(ctx context.Context, in *PlayHandRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Simulator_ServiceDesc.Streams[0], Simulator_StreamSearch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PlayHandRequest, SearchUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Simulator_StreamSearchClient = grpc.ServerStreamingClient[SearchUpdate]
+
+// SimulatorServer is the server API for Simulator service.
+// All implementations must embed UnimplementedSimulatorServer
+// for forward compatibility.
+type SimulatorServer interface {
+	// Seven cards from a fresh shuffle of a deck on the server
+	DealHand(context.Context, *DealHandRequest) (*OpeningHand, error)
+	// Play a hand out and return the result once the game is over
+	PlayHand(context.Context, *PlayHandRequest) (*GameResult, error)
+	// Play one hand against many shuffles of the rest of the deck
+	EvaluateHand(context.Context, *EvaluateRequest) (*SimResult, error)
+	// Play a hand, and compare against fresh six-card hands
+	Mulligan(context.Context, *EvaluateRequest) (*SimResult, error)
+	// Like PlayHand, but send progress as the search starts each turn. The
+	// last update is the result.
+	StreamSearch(*PlayHandRequest, grpc.ServerStreamingServer[SearchUpdate]) error
+	mustEmbedUnimplementedSimulatorServer()
+}
+
+// UnimplementedSimulatorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSimulatorServer struct{}
+
+func (UnimplementedSimulatorServer) DealHand(context.Context, *DealHandRequest) (*OpeningHand, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DealHand not implemented")
+}
+func (UnimplementedSimulatorServer) PlayHand(context.Context, *PlayHandRequest) (*GameResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlayHand not implemented")
+}
+func (UnimplementedSimulatorServer) EvaluateHand(context.Context, *EvaluateRequest) (*SimResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluateHand not implemented")
+}
+func (UnimplementedSimulatorServer) Mulligan(context.Context, *EvaluateRequest) (*SimResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Mulligan not implemented")
+}
+func (UnimplementedSimulatorServer) StreamSearch(*PlayHandRequest, grpc.ServerStreamingServer[SearchUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSearch not implemented")
+}
+func (UnimplementedSimulatorServer) mustEmbedUnimplementedSimulatorServer() {}
+func (UnimplementedSimulatorServer) testEmbeddedByValue()                   {}
+
+// UnsafeSimulatorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimulatorServer will
+// result in compilation errors.
+type UnsafeSimulatorServer interface {
+	mustEmbedUnimplementedSimulatorServer()
+}
+
+func RegisterSimulatorServer(s grpc.ServiceRegistrar, srv SimulatorServer) {
+	// If the following call pancis, it indicates UnimplementedSimulatorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Simulator_ServiceDesc, srv)
+}
+
+func _Simulator_DealHand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DealHandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServer).DealHand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Simulator_DealHand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServer).DealHand(ctx, req.(*DealHandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Simulator_PlayHand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayHandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServer).PlayHand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Simulator_PlayHand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServer).PlayHand(ctx, req.(*PlayHandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Simulator_EvaluateHand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServer).EvaluateHand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Simulator_EvaluateHand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServer).EvaluateHand(ctx, req.(*EvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Simulator_Mulligan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServer).Mulligan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Simulator_Mulligan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServer).Mulligan(ctx, req.(*EvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Simulator_StreamSearch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PlayHandRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SimulatorServer).StreamSearch(m, &grpc.GenericServerStream[PlayHandRequest, SearchUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Simulator_StreamSearchServer = grpc.ServerStreamingServer[SearchUpdate]
+
+// Simulator_ServiceDesc is the grpc.ServiceDesc for Simulator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Simulator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mtgserver.v1.Simulator",
+	HandlerType: (*SimulatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DealHand",
+			Handler:    _Simulator_DealHand_Handler,
+		},
+		{
+			MethodName: "PlayHand",
+			Handler:    _Simulator_PlayHand_Handler,
+		},
+		{
+			MethodName: "EvaluateHand",
+			Handler:    _Simulator_EvaluateHand_Handler,
+		},
+		{
+			MethodName: "Mulligan",
+			Handler:    _Simulator_Mulligan_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSearch",
+			Handler:       _Simulator_StreamSearch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "mtgserver.proto",
+}
@@ -0,0 +1,278 @@
+package rpc
+
+
+import (
+    "context"
+    "errors"
+
+    "github.com/charles-uno/mtgserver/api"
+    "github.com/charles-uno/mtgserver/lib"
+    "github.com/charles-uno/mtgserver/service"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+
+//go:generate buf generate
+
+
+// The gRPC side of the server. Each method converts its message to the
+// matching api type, makes the same service call as the HTTP handler, and
+// converts the reply back.
+
+
+type Server struct {
+    UnimplementedSimulatorServer
+    svc *service.Service
+}
+
+
+func NewServer(svc *service.Service) *Server {
+    return &Server{svc: svc}
+}
+
+
+// Same split as the HTTP status codes. A canceled call says so, rather than
+// blaming the server.
+func statusError(err error) error {
+    if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+        return status.FromContextError(err).Err()
+    }
+    code := codes.Internal
+    switch service.KindOf(err) {
+        case service.BadRequest:
+            code = codes.InvalidArgument
+        case service.NotFound:
+            code = codes.NotFound
+    }
+    return status.Error(code, err.Error())
+}
+
+
+// An opening hand message is all optional fields, so an empty one is fine,
+// but leaving it out altogether isn't
+var errNoHand = status.Error(codes.InvalidArgument, "hand is required")
+
+
+func (self *Server) DealHand(ctx context.Context, req *DealHandRequest) (*OpeningHand, error) {
+    oh, err := self.svc.DealHand(req.GetDeck())
+    if err != nil {
+        return nil, statusError(err)
+    }
+    return handToProto(oh), nil
+}
+
+
+func (self *Server) PlayHand(ctx context.Context, req *PlayHandRequest) (*GameResult, error) {
+    if req.GetHand() == nil {
+        return nil, errNoHand
+    }
+    game, err := self.svc.NewGame(ctx, handFromProto(req.GetHand()), req.GetFormat())
+    if err != nil {
+        return nil, statusError(err)
+    }
+    result, err := game.Play(nil)
+    if err != nil {
+        return nil, statusError(err)
+    }
+    return resultToProto(result), nil
+}
+
+
+func (self *Server) EvaluateHand(ctx context.Context, req *EvaluateRequest) (*SimResult, error) {
+    return self.simulate(ctx, lib.SimHand, req)
+}
+
+
+func (self *Server) Mulligan(ctx context.Context, req *EvaluateRequest) (*SimResult, error) {
+    return self.simulate(ctx, lib.SimMulligan, req)
+}
+
+
+func (self *Server) simulate(ctx context.Context, kind string, req *EvaluateRequest) (*SimResult, error) {
+    result, err := self.svc.Simulate(ctx, jobFromProto(kind, req))
+    if err != nil {
+        return nil, statusError(err)
+    }
+    return simResultToProto(result), nil
+}
+
+
+// A turn update as the search starts each turn, then the result. If the
+// client goes away, the game still plays out, but nothing more is sent.
+func (self *Server) StreamSearch(req *PlayHandRequest, stream grpc.ServerStreamingServer[SearchUpdate]) error {
+    if req.GetHand() == nil {
+        return errNoHand
+    }
+    game, err := self.svc.NewGame(stream.Context(), handFromProto(req.GetHand()), req.GetFormat())
+    if err != nil {
+        return statusError(err)
+    }
+    var sendErr error
+    result, err := game.Play(func(progress lib.TurnProgress) {
+        if sendErr == nil {
+            sendErr = stream.Send(&SearchUpdate{Update: &SearchUpdate_Turn{Turn: progressToProto(progress)}})
+        }
+    })
+    if err != nil {
+        return statusError(err)
+    }
+    if sendErr != nil {
+        return sendErr
+    }
+    return stream.Send(&SearchUpdate{Update: &SearchUpdate_Result{Result: resultToProto(result)}})
+}
+
+
+func handFromProto(m *OpeningHand) api.OpeningHand {
+    oh := api.OpeningHand{
+        Deck: m.GetDeck(),
+        Hand: m.GetHand(),
+        Library: m.GetLibrary(),
+        OnThePlay: m.GetOnThePlay(),
+        Verbose: m.GetVerbose(),
+        Game: int(m.GetGame()),
+        Opponent: m.GetOpponent(),
+        Search: m.GetSearch(),
+        Turns: int(m.GetTurns()),
+        TimeoutMillis: int(m.GetTimeoutMillis()),
+    }
+    if m.Seed != nil {
+        seed := m.GetSeed()
+        oh.Seed = &seed
+    }
+    if m.OpponentProfile != nil {
+        profile := profileFromProto(m.GetOpponentProfile())
+        oh.OpponentProfile = &profile
+    }
+    return oh
+}
+
+
+func handToProto(oh api.OpeningHand) *OpeningHand {
+    return &OpeningHand{
+        Deck: oh.Deck,
+        Hand: oh.Hand,
+        Library: oh.Library,
+        OnThePlay: oh.OnThePlay,
+        Verbose: oh.Verbose,
+        Game: int32(oh.Game),
+        Opponent: oh.Opponent,
+        Search: oh.Search,
+        Seed: oh.Seed,
+        Turns: int32(oh.Turns),
+        TimeoutMillis: int32(oh.TimeoutMillis),
+    }
+}
+
+
+func profileFromProto(m *OpponentProfile) api.OpponentProfile {
+    profile := api.OpponentProfile{Name: m.GetName()}
+    for _, d := range m.GetDisruptions() {
+        profile.Disruptions = append(profile.Disruptions, api.Disruption{
+            Kind: d.GetKind(),
+            Card: d.GetCard(),
+            Turn: int(d.GetTurn()),
+            Targets: d.GetTargets(),
+            Probability: d.GetProbability(),
+            X: int(d.GetX()),
+            Sideboard: d.GetSideboard(),
+        })
+    }
+    return profile
+}
+
+
+func jobFromProto(kind string, m *EvaluateRequest) api.JobRequest {
+    return api.JobRequest{
+        SimSpec: api.SimSpec{
+            Kind: kind,
+            Deck: m.GetDeck(),
+            Hand: m.GetHand(),
+            OnThePlay: m.OnThePlay,
+            Trials: int(m.GetTrials()),
+            Seed: m.Seed,
+            Search: m.GetSearch(),
+        },
+        Turns: int(m.GetTurns()),
+        TimeoutMillis: int(m.GetTimeoutMillis()),
+    }
+}
+
+
+func resultToProto(result api.GameResult) *GameResult {
+    m := &GameResult{
+        Success: result.Success,
+        Turn: int32(result.Turn),
+        OnThePlay: result.OnThePlay,
+        Seed: result.Seed,
+        Stats: &SearchStats{
+            Search: result.Stats.Search,
+            Expanded: int64(result.Stats.Expanded),
+            Pruned: int64(result.Stats.Pruned),
+            Millis: result.Stats.Millis,
+            DeadEnds: int64(result.Stats.DeadEnds),
+            OutOfBudget: result.Stats.OutOfBudget,
+        },
+        Text: result.Text,
+    }
+    for _, p := range result.Plays {
+        m.Plays = append(m.Plays, &PlayTag{Type: p.Type, Text: p.Text, Target: p.Target})
+    }
+    for _, e := range result.Events {
+        m.Events = append(m.Events, &Event{
+            Type: e.Type,
+            Turn: int32(e.Turn),
+            Cards: e.Cards,
+            Text: e.Text,
+            Hand: countsToProto(e.Hand),
+            Battlefield: countsToProto(e.Battlefield),
+            ManaPool: e.ManaPool,
+            LibrarySize: int32(e.LibrarySize),
+        })
+    }
+    return m
+}
+
+
+func countsToProto(counts map[string]int) map[string]int32 {
+    ret := make(map[string]int32, len(counts))
+    for name, n := range counts {
+        ret[name] = int32(n)
+    }
+    return ret
+}
+
+
+func progressToProto(progress api.TurnProgress) *TurnProgress {
+    return &TurnProgress{
+        Turn: int32(progress.Turn),
+        Frontier: int32(progress.Frontier),
+        Expanded: int64(progress.Expanded),
+        Pruned: int64(progress.Pruned),
+        Millis: progress.Millis,
+    }
+}
+
+
+func simResultToProto(result api.SimResult) *SimResult {
+    m := &SimResult{Games: tallyToProto(result.Games)}
+    if result.Mulligan != nil {
+        m.Mulligan = tallyToProto(*result.Mulligan)
+    }
+    return m
+}
+
+
+func tallyToProto(tally api.SimTally) *SimTally {
+    m := &SimTally{
+        Games: int32(tally.Games),
+        Successes: int32(tally.Successes),
+        Rate: tally.Rate,
+    }
+    for _, n := range tally.ByTurn {
+        m.ByTurn = append(m.ByTurn, int32(n))
+    }
+    return m
+}
@@ -0,0 +1,387 @@
+package service
+
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "math/rand"
+    "time"
+
+    "github.com/charles-uno/mtgserver/api"
+    "github.com/charles-uno/mtgserver/lib"
+)
+
+
+// What the server does, independent of how the request came in. The HTTP
+// handlers and the gRPC server both parse their own requests, call in here,
+// and turn errors back into their own status codes with KindOf.
+
+
+// Requests can ask for fewer turns or a shorter timeout, but not more
+type Limits struct {
+    DefaultTurns    int
+    MaxTurns        int
+    Timeout         time.Duration
+    MaxTimeout      time.Duration
+    ManaCap         int
+    MaxExpanded     int
+    MaxTrials       int
+}
+
+
+// Search budget for one game. Zero means the request didn't ask, so use the
+// default. Anything over the limit is an error, not clamped, so the caller
+// isn't surprised.
+func (self *Limits) Budget(turns int, timeoutMillis int) (lib.Budget, error) {
+    budget := lib.Budget{
+        MaxTurns: self.DefaultTurns,
+        Timeout: self.Timeout,
+        ManaCap: self.ManaCap,
+        MaxExpanded: self.MaxExpanded,
+    }
+    if turns < 0 || turns > self.MaxTurns {
+        return budget, badRequest(fmt.Errorf("turns must be between 1 and %d", self.MaxTurns))
+    }
+    if turns > 0 {
+        budget.MaxTurns = turns
+    }
+    timeout := time.Duration(timeoutMillis) * time.Millisecond
+    if timeout < 0 || timeout > self.MaxTimeout {
+        return budget, badRequest(fmt.Errorf("timeout must be at most %d ms", self.MaxTimeout.Milliseconds()))
+    }
+    if timeout > 0 {
+        budget.Timeout = timeout
+    }
+    return budget, nil
+}
+
+
+type Service struct {
+    Limits      Limits
+    // Print a colored replay of each game to stdout once it's over, for
+    // watching the model work
+    ShowGames   bool
+}
+
+
+// Who's to blame for an error, which each transport turns into a status code
+type Kind int
+
+
+const (
+    Internal Kind = iota
+    BadRequest
+    NotFound
+)
+
+
+type Error struct {
+    Kind    Kind
+    Err     error
+}
+
+
+func (self *Error) Error() string {
+    return self.Err.Error()
+}
+
+
+func (self *Error) Unwrap() error {
+    return self.Err
+}
+
+
+// Errors that didn't come through here are ours
+func KindOf(err error) Kind {
+    var e *Error
+    if errors.As(err, &e) {
+        return e.Kind
+    }
+    return Internal
+}
+
+
+func badRequest(err error) error {
+    return &Error{Kind: BadRequest, Err: err}
+}
+
+
+// Unknown deck names are the caller's fault. Anything else is ours.
+func deckError(err error) error {
+    if err == nil {
+        return nil
+    }
+    if errors.Is(err, lib.ErrUnknownDeck) {
+        return &Error{Kind: NotFound, Err: err}
+    }
+    // Too short to deal from, so no game can be played with it
+    if errors.Is(err, lib.ErrDeckTooSmall) {
+        return badRequest(err)
+    }
+    return err
+}
+
+
+// A game that can't be played out because of what was asked for, like a card
+// the engine doesn't know or a library too short to draw from, is the
+// caller's fault. Anything else went wrong in the engine.
+func gameError(err error) error {
+    if err == nil {
+        return nil
+    }
+    for _, target := range []error{
+        lib.ErrUnknownCard,
+        lib.ErrUnknownSearch,
+        lib.ErrNoBehavior,
+        lib.ErrLibraryExhausted,
+        lib.ErrTooManyCopies,
+    } {
+        if errors.Is(err, target) {
+            return badRequest(err)
+        }
+    }
+    return err
+}
+
+
+type loggerKey struct{}
+
+
+// Tag everything logged for a request, like with its ID
+func WithLogger(ctx context.Context, log *slog.Logger) context.Context {
+    return context.WithValue(ctx, loggerKey{}, log)
+}
+
+
+func Logger(ctx context.Context) *slog.Logger {
+    if log, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+        return log
+    }
+    return slog.Default()
+}
+
+
+// Seven cards off the top of a fresh shuffle
+func (self *Service) DealHand(deckName string) (api.OpeningHand, error) {
+    deck, err := lib.LoadDeck(deckName)
+    if err != nil {
+        return api.OpeningHand{}, deckError(err)
+    }
+    return api.OpeningHand{
+        Deck: deckName,
+        Hand: deck[:7],
+        Library: deck[7:],
+        OnThePlay: flip(),
+        Verbose: false,
+    }, nil
+}
+
+
+// The decklist, so the caller knows what it can board in
+func (self *Service) Decklist(deckName string) (api.Decklist, error) {
+    deck, err := lib.LoadDecklist(deckName)
+    return deck, deckError(err)
+}
+
+
+// Seven cards from the deck after sideboarding
+func (self *Service) Sideboard(deckName string, req api.SideboardRequest) (api.OpeningHand, error) {
+    deck, err := lib.LoadDecklist(deckName)
+    if err != nil {
+        return api.OpeningHand{}, deckError(err)
+    }
+    deck, err = deck.WithPlan(req.SideboardPlan)
+    if err != nil {
+        return api.OpeningHand{}, badRequest(err)
+    }
+    main := lib.Shuffled(deck.Main)
+    oh := api.OpeningHand{
+        Deck: deckName,
+        Hand: main[:7],
+        Library: main[7:],
+        OnThePlay: flip(),
+        Verbose: false,
+        Game: req.Game,
+        Opponent: req.Opponent,
+    }
+    if req.OnThePlay != nil {
+        oh.OnThePlay = *req.OnThePlay
+    }
+    return oh, nil
+}
+
+
+func flip() bool {
+    return rand.Intn(2) == 0
+}
+
+
+// Everything needed to play one game, checked and ready to go
+type Game struct {
+    hand api.OpeningHand
+    budget lib.Budget
+    profile lib.OpponentProfile
+    seed int64
+    format string
+    show bool
+    log *slog.Logger
+}
+
+
+// Play a hand the caller dealt. The library is shuffled and the opponent's
+// cards are rolled with the hand's seed, or a random one.
+func (self *Service) NewGame(ctx context.Context, hand api.OpeningHand, format string) (*Game, error) {
+    game := Game{hand: hand, seed: lib.NewSeed(), format: format, show: self.ShowGames, log: Logger(ctx)}
+    if hand.Seed != nil {
+        game.seed = *hand.Seed
+    }
+    game.hand.Library = lib.ShuffledSeed(hand.Library, game.seed)
+    err := self.check(&game)
+    if err != nil {
+        return nil, err
+    }
+    return &game, nil
+}
+
+
+// A game dealt from a deck on the server, end to end
+type DealRequest struct {
+    Deck            string
+    // The seed picks the draw, the coin flip, and which of the opponent's
+    // maybe cards they have, so the whole game can be replayed. Leave empty
+    // for a random one.
+    Seed            *int64
+    Opponent        string
+    // Games 2 and 3 are post-board
+    Game            int
+    Search          string
+    Turns           int
+    TimeoutMillis   int
+    // No play log unless asked
+    Format          string
+}
+
+
+func (self *Service) DealGame(ctx context.Context, req DealRequest) (*Game, error) {
+    game := Game{seed: lib.NewSeed(), format: req.Format, show: self.ShowGames, log: Logger(ctx)}
+    if game.format == "" {
+        game.format = lib.FormatNone
+    }
+    if req.Seed != nil {
+        game.seed = *req.Seed
+    }
+    decklist, err := lib.LoadDecklist(req.Deck)
+    if err != nil {
+        return nil, deckError(err)
+    }
+    deck := lib.ShuffledSeed(decklist.Main, game.seed)
+    game.hand = api.OpeningHand{
+        Deck: req.Deck,
+        Hand: deck[:7],
+        Library: deck[7:],
+        OnThePlay: game.seed % 2 == 0,
+        Verbose: false,
+        Game: req.Game,
+        Opponent: req.Opponent,
+        Search: req.Search,
+        Turns: req.Turns,
+        TimeoutMillis: req.TimeoutMillis,
+    }
+    err = self.check(&game)
+    if err != nil {
+        return nil, err
+    }
+    return &game, nil
+}
+
+
+// Work out the budget and opponent, and catch bad names, before starting to
+// play. None of this depends on where the hand came from.
+func (self *Service) check(game *Game) error {
+    err := lib.CheckSearch(game.hand.Search)
+    if err == nil {
+        err = lib.CheckFormat(game.format)
+    }
+    if err != nil {
+        return badRequest(err)
+    }
+    game.budget, err = self.Limits.Budget(game.hand.Turns, game.hand.TimeoutMillis)
+    if err != nil {
+        return err
+    }
+    game.profile, err = game.hand.Profile()
+    if err != nil {
+        return badRequest(err)
+    }
+    return nil
+}
+
+
+// Play the game out, calling onTurn (if given) as the search starts each turn
+func (self *Game) Play(onTurn func(lib.TurnProgress)) (api.GameResult, error) {
+    game, err := lib.NewGame(
+        self.hand.Deck,
+        self.hand.Library,
+        self.hand.Hand,
+        self.hand.OnThePlay,
+        self.hand.Verbose,
+        self.budget,
+        self.profile,
+        self.seed,
+    )
+    if err != nil {
+        return api.GameResult{}, gameError(err)
+    }
+    game.SetLogger(self.log)
+    if onTurn != nil {
+        game.OnTurn(onTurn)
+    }
+    game, err = game.Run(self.hand.Search)
+    if err != nil {
+        return api.GameResult{}, gameError(err)
+    }
+    result, err := game.Result(self.format)
+    if err != nil {
+        return result, badRequest(err)
+    }
+    result.Seed = self.seed
+    if self.show {
+        fmt.Println(game.Pretty())
+    }
+    return result, nil
+}
+
+
+// Check a simulation and load its deck, to run now or queue as a job
+func (self *Service) NewSimulation(ctx context.Context, req api.JobRequest) (*lib.Simulation, error) {
+    if req.Trials > self.Limits.MaxTrials {
+        return nil, badRequest(fmt.Errorf("trials must be at most %d", self.Limits.MaxTrials))
+    }
+    budget, err := self.Limits.Budget(req.Turns, req.TimeoutMillis)
+    if err != nil {
+        return nil, err
+    }
+    sim, err := lib.NewSimulation(req.SimSpec, budget)
+    if errors.Is(err, lib.ErrUnknownDeck) {
+        return nil, deckError(err)
+    }
+    if err != nil {
+        return nil, badRequest(err)
+    }
+    sim.SetLogger(Logger(ctx))
+    return sim, nil
+}
+
+
+// Run a simulation while the caller waits. Stops between games if ctx is
+// canceled.
+func (self *Service) Simulate(ctx context.Context, req api.JobRequest) (api.SimResult, error) {
+    sim, err := self.NewSimulation(ctx, req)
+    if err != nil {
+        return api.SimResult{}, err
+    }
+    result, err := sim.Run(ctx, func(lib.SimResult, int) {})
+    return result, gameError(err)
+}